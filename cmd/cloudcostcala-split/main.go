@@ -0,0 +1,81 @@
+// Command cloudcostcala-split partitions a billing CSV into one file per resource type,
+// e.g. aws-billing-VM.csv and aws-billing-Database.csv, keeping the original columns.
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/ozwilder/CloudCostCalaCLI/internal/billing"
+	"github.com/ozwilder/CloudCostCalaCLI/internal/config"
+)
+
+func main() {
+	input := flag.String("input", "", "Billing CSV file to split (required)")
+	provider := flag.String("provider", "aws", "Cloud provider of the billing file: aws, azure or gcp")
+	outputDir := flag.String("output-dir", ".", "Directory to write the per-type CSV files to")
+	prefix := flag.String("prefix", "", "Output filename prefix (default \"<provider>-billing\")")
+	skipRows := flag.Int("skip-rows", 0, "Rows to skip before the header")
+	detectEncoding := flag.Bool("billing-encoding-detect", false, "Auto-detect billing file encoding (UTF-8, UTF-16, Windows-1252)")
+	maskResourceID := flag.Bool("mask-resource-id", false, "Replace resource IDs with MASKED-<hash> in the output files, like fieldMasking.maskResourceId")
+	flag.Parse()
+
+	if *input == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *prefix == "" {
+		*prefix = *provider + "-billing"
+	}
+
+	opts := billing.ParseOptions{
+		DetectEncoding: *detectEncoding,
+		SkipRows:       map[string]int{*provider: *skipRows},
+		Masking:        config.FieldMaskingConfig{MaskResourceID: *maskResourceID},
+	}
+	header, byType, err := billing.SplitCSVByType(*input, *provider, opts)
+	if err != nil {
+		log.Fatalf("Error splitting billing file: %v", err)
+	}
+
+	if err := os.MkdirAll(*outputDir, 0755); err != nil {
+		log.Fatalf("Error creating output directory: %v", err)
+	}
+
+	types := make([]string, 0, len(byType))
+	for resourceType := range byType {
+		types = append(types, resourceType)
+	}
+	sort.Strings(types)
+
+	for _, resourceType := range types {
+		path := filepath.Join(*outputDir, fmt.Sprintf("%s-%s.csv", *prefix, resourceType))
+		if err := writeCSV(path, header, byType[resourceType]); err != nil {
+			log.Fatalf("Error writing %s: %v", path, err)
+		}
+		fmt.Printf("  ✓ %s (%d rows)\n", path, len(byType[resourceType]))
+	}
+}
+
+// writeCSV writes a header and rows to a new CSV file
+func writeCSV(path string, header []string, rows [][]string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	if err := writer.WriteAll(rows); err != nil {
+		return err
+	}
+	return file.Close()
+}
@@ -1,102 +1,644 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+	_ "time/tzdata" // billing.azure.timezone works on hosts without zoneinfo
 
+	"github.com/ozwilder/CloudCostCalaCLI/internal/analysis"
 	"github.com/ozwilder/CloudCostCalaCLI/internal/assets"
 	"github.com/ozwilder/CloudCostCalaCLI/internal/billing"
 	"github.com/ozwilder/CloudCostCalaCLI/internal/config"
+	inventorypkg "github.com/ozwilder/CloudCostCalaCLI/internal/inventory"
+	"github.com/ozwilder/CloudCostCalaCLI/internal/metrics"
 	"github.com/ozwilder/CloudCostCalaCLI/internal/models"
+	"github.com/ozwilder/CloudCostCalaCLI/internal/workflow"
+	"github.com/ozwilder/CloudCostCalaCLI/pkg/cloudcost"
 	"github.com/ozwilder/CloudCostCalaCLI/pkg/output"
+	"github.com/ozwilder/CloudCostCalaCLI/pkg/sdk"
+	"github.com/xuri/excelize/v2"
 )
 
+// version is the tool version, set at build time with -ldflags "-X main.version=..."
+var version = "dev"
+
+// exitOutputError is the exit code when -validate-output finds the Excel file does not
+// match the aggregated data
+const exitOutputError = 3
+
 func main() {
 	configPath := flag.String("config", "config.example.json", "Path to configuration file")
 	outputFile := flag.String("output", "cloud-assets-inventory.xlsx", "Output Excel file path")
+	failOnThreshold := flag.Bool("fail-on-threshold", false, "Exit with a non-zero code when a synthetic-unit threshold is exceeded")
+	detectEncoding := flag.Bool("billing-encoding-detect", false, "Auto-detect billing file encoding (UTF-8, UTF-16, Windows-1252)")
+	accountID := flag.String("cloud-account-id", "", "Account/subscription/project ID to tag billing records that lack an account column")
+	comparePeriods := flag.Bool("compare-periods", false, "Aggregate each billing period separately and compare them month over month")
+	conversionTable := flag.String("conversion-table", "", "Comma-separated unit systems from conversionTable to add as Excel columns, or \"all\"")
+	awsBilling := flag.String("aws-billing", "", "AWS billing file path, overriding the config (\"-\" reads stdin)")
+	azureBilling := flag.String("azure-billing", "", "Azure billing file path, overriding the config (\"-\" reads stdin)")
+	gcpBilling := flag.String("gcp-billing", "", "GCP billing file path, overriding the config (\"-\" reads stdin)")
+	dryRun := flag.Bool("dry-run", false, "Validate config and process billing files without writing any output files")
+	compressOutput := flag.Bool("output-compression", false, "Replace the Excel file with a ZIP archive containing it")
+	hoursPrecision := flag.Int("instance-hours-precision", 4, "Decimal places to round billed instance-hours to (negative disables rounding)")
+	filterTypes := flag.String("filter-types", "", "Comma-separated asset types to restrict processing to (e.g. VM,Database)")
+	telemetryDisable := flag.Bool("telemetry-disable", false, "Disable anonymous usage analytics")
+	rawOutput := flag.String("raw-output", "", "Also write every billing record to this CSV file for auditing")
+	includeRaw := flag.Bool("excel-include-raw", false, "Add a \"Raw Records\" sheet with every parsed billing record to the Excel file")
+	outputMetadata := flag.Bool("output-metadata", false, "Write run metadata to a <output>.meta.json sidecar file")
+	ephemeralOnly := flag.Bool("ephemeral-only", false, "Show only asset types found in billing but not in the current inventory")
+	skipRows := flag.Int("billing-file-skip-rows", 0, "Rows to skip before the header in every billing CSV, overriding billing.*.skipRows")
+	suggestTags := flag.Bool("suggest-tags", false, "Suggest tags from resource name patterns and add a \"Tags\" sheet")
+	outputGraph := flag.String("output-graph", "", "Write a DOT graph of asset types billed in the same project to this file")
+	auditLog := flag.String("audit-log", "", "Write billing rows discarded while parsing to this JSON file")
+	marketplace := flag.Bool("cloud-marketplace", false, "Report AWS Marketplace charges as a separate \"Marketplace\" asset type")
+	regionalPricing := flag.String("regional-pricing-file", "", "JSON file mapping regions to instance-hour coefficients (e.g. {\"eu-west-1\": 1.15})")
+	splitByPeriod := flag.Bool("output-split-by-period", false, "Also write one Excel file per billing period (<name>-<YYYY-MM>.xlsx) when several periods are loaded")
+	noCombined := flag.Bool("no-combined", false, "With -output-split-by-period, skip the combined Excel file")
+	benchmarkFile := flag.String("benchmark", "", "JSON file of industry baselines ({assetType, p50Units, p95Units}) to rank synthetic units against")
+	configLint := flag.Bool("config-lint", false, "Check the config for deprecated fields, print migration hints and exit")
+	metricsEndpoint := flag.String("metrics-endpoint", "", "Push billing record counts to statsd://host:port or an http(s) Prometheus pushgateway")
+	scenarioFlag := flag.String("scenario", "", "Comma-separated migrations FROM:TO:FRACTION[:RATIO] to simulate in a \"Scenario\" sheet (e.g. VM:Container:0.3)")
+	maxFileSize := flag.Int("max-file-size", 0, "Refuse billing files larger than this many MB (0 means no limit)")
+	lineageFile := flag.String("lineage", "", "Write a JSON file mapping each asset type to its source billing rows (e.g. report.xlsx.lineage.json)")
+	summaryFormat := flag.String("summary-format", "unicode", "Console summary format: unicode, plain or json")
+	awsAccountMap := flag.String("aws-account-map", "", "JSON file mapping AWS account IDs to friendly names, used as the project")
+	jsonPretty := flag.Bool("output-json-pretty", true, "Indent JSON written by workflow write-json steps (-output-json-pretty=false writes compact JSON)")
+	costThreshold := flag.Float64("cost-threshold-percent", 0, "Warn about asset types above this percentage of total synthetic units (e.g. 60) and mark them in Excel")
+	trace := flag.Bool("trace", false, "Print every calculation step from billed instance-hours to synthetic units per asset type")
+	splitBillingBy := flag.String("split-billing-by", "", "Add a sheet aggregating billing records per provider, region, project or tag:<key>")
+	inventoryFile := flag.String("inventory", "", "Current asset inventory file to enrich with billing data")
+	inventoryFormat := flag.String("inventory-format", "json", "Inventory file format: json, csv or tfstate")
+	waitForFiles := flag.Bool("wait-for-files", false, "Poll every 30s until all configured billing files exist before starting")
+	waitTimeout := flag.Duration("wait-timeout", 10*time.Minute, "How long -wait-for-files waits before giving up")
+	periodAuto := flag.Bool("billing-period-auto", false, "Infer the billing period from the most common record period instead of the first record's")
+	githubPRComment := flag.Bool("github-pr-comment", false, "Append a Markdown diff of synthetic units per asset type to $GITHUB_STEP_SUMMARY")
+	prCommentBaseline := flag.String("github-pr-comment-baseline", "", "JSON report of a previous run (workflow write-json output) to diff -github-pr-comment against")
+	transformScript := flag.String("transform-script", "", "Starlark (.star) script defining transform(record) to rewrite each billing record")
+	validateOutput := flag.Bool("validate-output", false, "Read the Excel file back after writing and verify its synthetic units match")
+	azureMonitorCheck := flag.Bool("azure-monitor-crosscheck", false, "Compare billed Azure VM hours with Azure Monitor CPU hours (credentials from AZURE_SUBSCRIPTION_ID, AZURE_TENANT_ID, AZURE_CLIENT_ID, AZURE_CLIENT_SECRET)")
 	flag.Parse()
 
+	setFlags := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { setFlags[f.Name] = true })
+
+	// Fall back to CCC_CONFIG_PATH when -config is not given explicitly
+	if envPath := os.Getenv("CCC_CONFIG_PATH"); envPath != "" && !setFlags["config"] {
+		*configPath = envPath
+	}
+
+	if !slices.Contains(output.SummaryFormats, *summaryFormat) {
+		log.Fatalf("Invalid -summary-format %q: expected one of %s", *summaryFormat, strings.Join(output.SummaryFormats, ", "))
+	}
+
+	if *splitBillingBy != "" {
+		if err := billing.ValidateSplitDimension(*splitBillingBy); err != nil {
+			log.Fatalf("Invalid -split-billing-by: %v", err)
+		}
+	}
+
+	if *costThreshold < 0 || *costThreshold > 100 {
+		log.Fatalf("Invalid -cost-threshold-percent %g: expected a percentage from 0 to 100", *costThreshold)
+	}
+	maxShare := *costThreshold / 100 // CheckShareThreshold takes a fraction of the total
+
+	if *configLint {
+		os.Exit(lintConfigFile(*configPath))
+	}
+
 	// Load config
 	cfg, err := config.LoadConfig(*configPath)
 	if err != nil {
 		log.Fatalf("Error loading config: %v", err)
 	}
 
+	// Billing file flags take precedence over config and environment
+	if *awsBilling != "" {
+		cfg.Billing.AWS.FilePath = *awsBilling
+	}
+	if *azureBilling != "" {
+		cfg.Billing.Azure.FilePath = *azureBilling
+	}
+	if *gcpBilling != "" {
+		cfg.Billing.GCP.FilePath = *gcpBilling
+	}
+
+	// Stdin can only be read once, so at most one provider may use it
+	stdinProviders := 0
+	for _, path := range []string{cfg.Billing.AWS.FilePath, cfg.Billing.Azure.FilePath, cfg.Billing.GCP.FilePath} {
+		if path == billing.StdinPath {
+			stdinProviders++
+		}
+	}
+	if stdinProviders > 1 {
+		log.Fatal("Only one billing file can be read from stdin")
+	}
+
+	// Config (or CCC_OUTPUT_FILE) supplies the output file unless -output is given
+	if cfg.Output.Filename != "" && !setFlags["output"] {
+		*outputFile = cfg.Output.Filename
+	}
+
+	if *regionalPricing != "" {
+		coefficients, err := config.LoadRegionalPricing(*regionalPricing)
+		if err != nil {
+			log.Fatalf("Error loading regional pricing: %v", err)
+		}
+		cfg.SyntheticUnits.RegionCoefficients = coefficients
+	}
+
+	migrations := make([]analysis.Migration, 0)
+	for _, s := range splitList(*scenarioFlag) {
+		m, err := analysis.ParseMigration(s)
+		if err != nil {
+			log.Fatalf("Error parsing -scenario: %v", err)
+		}
+		migrations = append(migrations, m)
+	}
+
+	if *telemetryDisable {
+		cfg.Telemetry.Enabled = false
+	}
+
+	// Report every config problem at once
+	if errs := config.Validate(cfg); len(errs) > 0 {
+		for _, e := range errs {
+			fmt.Fprintf(os.Stderr, "Config error: %v\n", e)
+		}
+		log.Fatalf("Invalid config: %d error(s)", len(errs))
+	}
+
 	fmt.Println("╔══════════════════════════════════════════════════════════════╗")
 	fmt.Println("║         CloudCostCalaCLI - Cloud Asset Inventory            ║")
 	fmt.Println("╚══════════════════════════════════════════════════════════════╝")
 	fmt.Printf("\nConfiguration: %s\n", *configPath)
+	if cfg.Telemetry.Enabled {
+		fmt.Println("Telemetry: enabled (asset type counts, synthetic unit totals and tool version; no file paths or resource IDs). Disable with -telemetry-disable")
+	}
 
-	// Collect assets from billing files
-	allAssets := make([]models.Asset, 0)
-	allBillingRecords := make([]models.BillingRecord, 0)
+	if *waitForFiles {
+		paths := []string{cfg.Billing.AWS.FilePath, cfg.Billing.Azure.FilePath, cfg.Billing.GCP.FilePath}
+		if err := billing.WaitForFiles(paths, billing.WaitPollInterval, *waitTimeout); err != nil {
+			log.Fatalf("Error waiting for billing files: %v", err)
+		}
+	}
 
-	// Process AWS billing
-	if cfg.Billing.AWS.FilePath != "" {
-		fmt.Println("\n[AWS] Processing billing file...")
-		awsRecords, err := billing.ParseBillingFile(cfg.Billing.AWS.FilePath, "aws")
+	parseOpts := billing.ParseOptions{
+		DetectEncoding: *detectEncoding,
+		AccountID:      *accountID,
+		GCPFormat:      cfg.Billing.GCP.Format,
+		SkipRows:       cfg.Billing.SkipRows(),
+		Marketplace:    *marketplace,
+		MaxFileSizeMB:  *maxFileSize,
+		AzureTimezone:  cfg.Billing.Azure.Timezone,
+		Masking:        cfg.FieldMasking,
+	}
+	if *awsAccountMap != "" {
+		names, err := config.LoadAccountMap(*awsAccountMap)
 		if err != nil {
-			log.Printf("Warning: Failed to parse AWS billing: %v", err)
-		} else {
-			allBillingRecords = append(allBillingRecords, awsRecords...)
-			fmt.Printf("  ✓ Loaded %d AWS billing records\n", len(awsRecords))
+			log.Fatalf("Error loading AWS account map: %v", err)
+		}
+		parseOpts.AWSAccountMap = names
+	}
+	if *transformScript != "" {
+		transform, err := billing.LoadTransform(*transformScript)
+		if err != nil {
+			log.Fatalf("Error loading transform script: %v", err)
+		}
+		parseOpts.Transform = transform
+	}
+	if *auditLog != "" || *metricsEndpoint != "" {
+		parseOpts.Audit = &billing.AuditLog{}
+	}
+	if setFlags["billing-file-skip-rows"] {
+		for provider := range parseOpts.SkipRows {
+			parseOpts.SkipRows[provider] = *skipRows
 		}
 	}
 
-	// Process Azure billing
-	if cfg.Billing.Azure.FilePath != "" {
-		fmt.Println("\n[Azure] Processing billing file...")
-		azureRecords, err := billing.ParseBillingFile(cfg.Billing.Azure.FilePath, "azure")
+	inventory := make([]models.Asset, 0)
+	if *inventoryFile != "" {
+		inventory, err = inventorypkg.LoadInventory(*inventoryFile, *inventoryFormat, cfg.Inventory)
 		if err != nil {
-			log.Printf("Warning: Failed to parse Azure billing: %v", err)
+			log.Fatalf("Error loading inventory: %v", err)
+		}
+		fmt.Printf("\n[Inventory] Loaded %d assets from %s\n", len(inventory), *inventoryFile)
+	}
+
+	// Warnings are echoed as they happen and collected for the run metadata
+	warnings := make([]string, 0)
+	parsedByProvider := make(map[string]int)
+
+	// Parse, normalize, enrich and aggregate billing data
+	pipeline := sdk.NewPipeline(cfg, sdk.AnalysisOptions{
+		ParseOptions:           parseOpts,
+		Inventory:              inventory,
+		InstanceHoursPrecision: hoursPrecision,
+		Types:                  splitList(*filterTypes),
+		EphemeralOnly:          *ephemeralOnly,
+		BillingPeriodAuto:      *periodAuto,
+		Hooks: cloudcost.Hooks{
+			BeforeParse: func(provider string) {
+				fmt.Printf("\n[%s] Processing billing file...\n", provider)
+			},
+			AfterParse: func(provider string, records []models.BillingRecord, err error) {
+				if err != nil {
+					log.Printf("Warning: Failed to parse %s billing: %v", provider, err)
+					warnings = append(warnings, fmt.Sprintf("failed to parse %s billing: %v", provider, err))
+					return
+				}
+				fmt.Printf("  ✓ Loaded %d %s billing records\n", len(records), provider)
+				parsedByProvider[provider] = len(records)
+				for _, w := range billing.LintBillingRecords(records) {
+					fmt.Fprintf(os.Stderr, "  ⚠ Lint: %s\n", w)
+					warnings = append(warnings, fmt.Sprintf("%s lint: %s", provider, w))
+				}
+			},
+		},
+	})
+
+	aggregated, err := pipeline.Run(context.Background())
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	if *auditLog != "" && *dryRun {
+		fmt.Printf("\n[Dry Run] Would write audit log: %s (%d discarded billing row(s))\n",
+			*auditLog, len(parseOpts.Audit.Entries))
+	} else if *auditLog != "" {
+		if err := parseOpts.Audit.WriteJSON(*auditLog); err != nil {
+			log.Fatalf("Error writing audit log: %v", err)
+		}
+		fmt.Printf("\n[Audit] %d discarded billing row(s) written to %s\n", len(parseOpts.Audit.Entries), *auditLog)
+		for reason, count := range parseOpts.Audit.CountByReason() {
+			fmt.Printf("  %d: %s\n", count, reason)
+		}
+	}
+
+	if *metricsEndpoint != "" && *dryRun {
+		fmt.Printf("\n[Dry Run] Would push billing record counts to %s\n", *metricsEndpoint)
+	} else if *metricsEndpoint != "" {
+		skipped := parseOpts.Audit.CountByProvider()
+		counters := make([]metrics.Counter, 0)
+		for provider, parsed := range parsedByProvider {
+			name := strings.ToLower(provider)
+			counters = append(counters,
+				metrics.Counter{Name: "billing_records_parsed", Provider: name, Value: parsed},
+				metrics.Counter{Name: "billing_records_skipped", Provider: name, Value: skipped[provider]})
+		}
+		if err := metrics.Push(*metricsEndpoint, counters); err != nil {
+			log.Printf("Warning: Failed to push metrics: %v", err)
+			warnings = append(warnings, fmt.Sprintf("failed to push metrics: %v", err))
 		} else {
-			allBillingRecords = append(allBillingRecords, azureRecords...)
-			fmt.Printf("  ✓ Loaded %d Azure billing records\n", len(azureRecords))
+			fmt.Printf("\n[Metrics] Pushed billing record counts to %s\n", *metricsEndpoint)
 		}
 	}
 
-	// Process GCP billing
-	if cfg.Billing.GCP.FilePath != "" {
-		fmt.Println("\n[GCP] Processing billing file...")
-		gcpRecords, err := billing.ParseBillingFile(cfg.Billing.GCP.FilePath, "gcp")
+	allBillingRecords := pipeline.Records()
+	recordsByProvider := pipeline.RecordsByProvider()
+
+	// The filename template needs the billing period, so it is rendered after parsing
+	if cfg.Output.OutputFilenameTemplate != "" {
+		data := output.FilenameData{
+			Period:    pipeline.BillingPeriod(),
+			Timestamp: time.Now().Format(output.FilenameTimestampFormat),
+			Provider:  "all",
+		}
+		if len(recordsByProvider) == 1 {
+			data.Provider = getRecordKeys(recordsByProvider)[0]
+		}
+		filename, err := output.RenderFilename(cfg.Output.OutputFilenameTemplate, data)
 		if err != nil {
-			log.Printf("Warning: Failed to parse GCP billing: %v", err)
-		} else {
-			allBillingRecords = append(allBillingRecords, gcpRecords...)
-			fmt.Printf("  ✓ Loaded %d GCP billing records\n", len(gcpRecords))
+			log.Fatalf("Error rendering output filename: %v", err)
 		}
+		*outputFile = filename
 	}
 
-	if len(allBillingRecords) == 0 {
-		log.Fatal("No billing records loaded. Check config file paths.")
+	if *comparePeriods {
+		runComparePeriods(pipeline, *outputFile, *dryRun)
+		return
 	}
 
-	// Normalize billing data to instance-hours
 	fmt.Println("\n[Processing] Normalizing billing metrics...")
-	billingPeriod := billing.GetBillingPeriod(allBillingRecords)
-	avgInstancesByType := billing.AggregateByType(allBillingRecords, billingPeriod)
+	billingPeriod := pipeline.BillingPeriod()
 	fmt.Printf("  ✓ Billing period: %s\n", billingPeriod)
-	fmt.Printf("  ✓ Asset types found: %v\n", getKeys(avgInstancesByType))
+	fmt.Printf("  ✓ Asset types found: %v\n", getKeys(pipeline.AvgInstancesByType()))
 
-	// Enrich assets with billing data
 	fmt.Println("\n[Processing] Enriching assets...")
-	enrichedAssets := assets.EnrichAssets(allAssets, avgInstancesByType, cfg.SyntheticUnits)
-	fmt.Printf("  ✓ Enriched %d asset types\n", len(enrichedAssets))
+	fmt.Printf("  ✓ Enriched %d asset types\n", len(aggregated))
+	if *ephemeralOnly {
+		fmt.Printf("  ✓ Ephemeral-only asset types found: %d\n", len(aggregated))
+	}
 
-	// Aggregate for output
 	fmt.Println("\n[Processing] Aggregating results...")
-	aggregated := assets.AggregateForOutput(enrichedAssets)
+
+	// Rank each type against industry baselines
+	var baselines []cloudcost.IndustryBaseline
+	benchmarked := make(map[string]bool)
+	if *benchmarkFile != "" {
+		baselines, err = cloudcost.LoadIndustryBaselines(*benchmarkFile)
+		if err != nil {
+			log.Fatalf("Error loading benchmark: %v", err)
+		}
+		aggregated = cloudcost.ApplyBenchmark(aggregated, baselines)
+		for _, b := range baselines {
+			benchmarked[b.AssetType] = true
+		}
+	}
+
+	if *trace {
+		printTrace(aggregated, allBillingRecords, cfg.SyntheticUnits)
+	}
+
+	// Check synthetic-unit thresholds
+	violations := pipeline.CheckThresholds(aggregated)
+	for _, v := range violations {
+		fmt.Fprintf(os.Stderr, "Warning: %s synthetic units %d exceed limit %d\n", v.AssetType, v.Computed, v.Limit)
+		warnings = append(warnings, fmt.Sprintf("%s synthetic units %d exceed limit %d", v.AssetType, v.Computed, v.Limit))
+	}
+
+	// Flag asset types that dominate the total
+	for _, v := range cloudcost.CheckShareThreshold(aggregated, maxShare) {
+		msg := fmt.Sprintf("%s accounts for %.0f%% of total synthetic units (threshold %g%%)", v.AssetType, v.Share*100, *costThreshold)
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", msg)
+		warnings = append(warnings, msg)
+	}
+
+	// Compare billed Azure VM hours with the hours Azure Monitor saw the VMs running
+	if *azureMonitorCheck {
+		discrepancies, err := pipeline.CrossCheckAzureMonitor(context.Background(), cloudcost.AzureCredentials{
+			SubscriptionID: os.Getenv("AZURE_SUBSCRIPTION_ID"),
+			TenantID:       os.Getenv("AZURE_TENANT_ID"),
+			ClientID:       os.Getenv("AZURE_CLIENT_ID"),
+			ClientSecret:   os.Getenv("AZURE_CLIENT_SECRET"),
+		})
+		if err != nil {
+			log.Printf("Warning: %v", err)
+			warnings = append(warnings, err.Error())
+		} else {
+			fmt.Printf("  ✓ Azure Monitor cross-check: %d VM discrepancy(ies)\n", len(discrepancies))
+		}
+		for _, d := range discrepancies {
+			fmt.Fprintf(os.Stderr, "Warning: Azure Monitor: %s\n", d)
+			warnings = append(warnings, fmt.Sprintf("Azure Monitor: %s", d))
+		}
+	}
+
+	// Compare billed cost with the cost implied by synthetic units
+	for _, w := range analysis.SanityCostCheck(aggregated, cfg.Pricing.CostPerSyntheticUnit) {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", w)
+		warnings = append(warnings, w)
+	}
 
 	// Print summary table
-	output.PrintSummaryTable(aggregated)
+	if err := output.PrintSummary(aggregated, *summaryFormat); err != nil {
+		log.Fatalf("Error printing summary: %v", err)
+	}
 
-	// Generate Excel file
-	fmt.Printf("\n[Output] Generating Excel file: %s\n", *outputFile)
-	if err := output.WriteExcel(*outputFile, aggregated); err != nil {
-		log.Fatalf("Error writing Excel: %v", err)
+	// Collect optional Excel sheets
+	extraSheets := make([]output.SheetWriter, 0)
+
+	if *suggestTags {
+		suggestions := analysis.SuggestTagsForRecords(allBillingRecords, analysis.DefaultTagPatterns)
+		output.PrintTagSuggestions(suggestions)
+		extraSheets = append(extraSheets, func(f *excelize.File) error {
+			return output.WriteTagsSheet(f, suggestions)
+		})
+	}
+	if len(migrations) > 0 {
+		scenario := analysis.SimulateScenario(aggregated, migrations, cfg.SyntheticUnits)
+		output.PrintScenario(aggregated, scenario)
+		extraSheets = append(extraSheets, func(f *excelize.File) error {
+			return output.WriteScenarioSheet(f, aggregated, scenario)
+		})
+	}
+	if byGroup := pipeline.ByVPCGroup(); byGroup != nil {
+		extraSheets = append(extraSheets, func(f *excelize.File) error {
+			return output.WriteGroupSheet(f, "By VPC Group", byGroup)
+		})
+	}
+
+	if *splitBillingBy != "" {
+		byGroup, err := pipeline.ByDimension(*splitBillingBy)
+		if err != nil {
+			log.Fatalf("Error splitting billing records: %v", err)
+		}
+		fmt.Printf("  ✓ Split billing records into %d group(s) by %s\n", len(byGroup), *splitBillingBy)
+		sheet := "By " + strings.ReplaceAll(*splitBillingBy, ":", " ")
+		extraSheets = append(extraSheets, func(f *excelize.File) error {
+			return output.WriteGroupSheet(f, sheet, byGroup)
+		})
+	}
+
+	// Compare billed rates per unit when the exports include cost
+	if prices := analysis.ComputeUnitPriceList(aggregated, allBillingRecords); len(prices) > 0 {
+		fmt.Printf("  ✓ Unit prices computed for %d asset type/region pairs\n", len(prices))
+		extraSheets = append(extraSheets, func(f *excelize.File) error {
+			return output.WriteUnitPriceSheet(f, prices)
+		})
+	}
+
+	// Break usage down by hour of day when the billing data is hourly
+	if billing.HasHourlyData(allBillingRecords) {
+		byHour := make(map[string][24]float64)
+		for assetType, records := range billing.SplitByType(allBillingRecords) {
+			byHour[assetType] = billing.AggregateByHourOfDay(records)
+		}
+		extraSheets = append(extraSheets, func(f *excelize.File) error {
+			return output.WriteUsageByHourSheet(f, byHour)
+		})
+	}
+	for _, provider := range getRecordKeys(recordsByProvider) {
+		records := recordsByProvider[provider]
+		if !billing.HasHourlyData(records) {
+			continue
+		}
+		sheetName := provider + " Heat Map"
+		extraSheets = append(extraSheets, func(f *excelize.File) error {
+			return output.WriteProviderHeatMap(f, sheetName, records)
+		})
+	}
+
+	// Compare with the same month a year earlier when both are loaded
+	growth := analysis.YoYGrowthRate(analysis.PeriodAvgsByType(billing.AggregateByTypePeriod(allBillingRecords, cfg.SyntheticUnits)))
+	if len(growth) > 0 {
+		fmt.Printf("  ✓ Year-over-year growth computed for %d asset types\n", len(growth))
+	}
+
+	var conversions []output.ConversionColumn
+	if *conversionTable != "" {
+		conversions = conversionColumns(*conversionTable, cfg.ConversionTable)
+	}
+
+	// assetColumns returns the column writers for an Excel file's asset sheets, computed
+	// from the rows that file holds so per-period files get their own CPI and highlights
+	assetColumns := func(rows []models.AggregatedOutput) []output.SheetWriter {
+		writers := make([]output.SheetWriter, 0)
+		if len(cfg.BudgetedUnits) > 0 {
+			writers = append(writers, output.AddCPIColumn(rows))
+		}
+		if baselines != nil {
+			writers = append(writers, output.AddBenchmarkColumn(cloudcost.ApplyBenchmark(rows, baselines), benchmarked))
+		}
+		if len(growth) > 0 {
+			writers = append(writers, output.AddYoYColumn(growth))
+		}
+		if conversions != nil {
+			writers = append(writers, output.AddConversionColumns(conversions))
+		}
+
+		// Highlight after the column writers so whole rows are marked
+		dominant := make([]string, 0)
+		for _, v := range cloudcost.CheckShareThreshold(rows, maxShare) {
+			dominant = append(dominant, v.AssetType)
+		}
+		if len(dominant) > 0 {
+			writers = append(writers, output.HighlightAssetRows(dominant))
+		}
+		return writers
+	}
+	extraSheets = append(extraSheets, assetColumns(aggregated)...)
+
+	if *includeRaw {
+		if len(allBillingRecords) > output.MaxRawRecordRows-1 {
+			log.Printf("Warning: Raw Records sheet limited to the first %d of %d billing records",
+				output.MaxRawRecordRows-1, len(allBillingRecords))
+		}
+		extraSheets = append(extraSheets, func(f *excelize.File) error {
+			return output.WriteRawRecordsSheet(f, allBillingRecords)
+		})
+	}
+
+	if *dryRun {
+		fmt.Printf("\n[Dry Run] Would write Excel file: %s\n", *outputFile)
+		if *rawOutput != "" {
+			fmt.Printf("[Dry Run] Would write raw billing CSV: %s\n", *rawOutput)
+		}
+		if *outputGraph != "" {
+			fmt.Printf("[Dry Run] Would write dependency graph: %s\n", *outputGraph)
+		}
+		if *lineageFile != "" {
+			fmt.Printf("[Dry Run] Would write lineage: %s\n", *lineageFile)
+		}
+		if *outputMetadata {
+			fmt.Printf("[Dry Run] Would write run metadata: %s%s\n", *outputFile, output.MetadataSuffix)
+		}
+		if len(cfg.Workflow.Steps) > 0 {
+			fmt.Printf("[Dry Run] Would run %d workflow step(s)\n", len(cfg.Workflow.Steps))
+		}
+	} else {
+		// Write one file per period when several are loaded
+		writeCombined := true
+		var byPeriod map[string][]models.AggregatedOutput
+		if *splitByPeriod {
+			byPeriod = pipeline.ByPeriod()
+		}
+		if len(byPeriod) > 1 {
+			fmt.Printf("\n[Output] Generating %d per-period Excel files\n", len(byPeriod))
+			for _, period := range sortedSheetKeys(byPeriod) {
+				path := periodFilename(*outputFile, period)
+				rows := byPeriod[period]
+				if err := output.WriteExcel(path, rows, assetColumns(rows)...); err != nil {
+					log.Fatalf("Error writing Excel: %v", err)
+				}
+				fmt.Printf("  ✓ %s\n", path)
+			}
+			writeCombined = !*noCombined
+		}
+
+		if writeCombined {
+			fmt.Printf("\n[Output] Generating Excel file: %s\n", *outputFile)
+			written := writeCombinedExcel(*outputFile, aggregated, pipeline, extraSheets)
+			fmt.Println("  ✓ Excel file generated successfully!")
+
+			if *validateOutput {
+				for _, sheet := range sortedSheetKeys(written) {
+					if err := output.ValidateExcel(*outputFile, sheet, written[sheet]); err != nil {
+						fmt.Fprintf(os.Stderr, "Output validation failed:\n%v\n", err)
+						os.Exit(exitOutputError)
+					}
+				}
+				fmt.Println("  ✓ Excel file validated against aggregated output")
+			}
+		} else {
+			fmt.Println("\n[Output] Skipping combined Excel file (-no-combined)")
+		}
+
+		if *rawOutput != "" {
+			if err := output.WriteRawCSV(*rawOutput, allBillingRecords); err != nil {
+				log.Fatalf("Error writing raw CSV: %v", err)
+			}
+			fmt.Printf("  ✓ Raw billing records written to %s\n", *rawOutput)
+		}
+
+		if *compressOutput && writeCombined {
+			archivePath, err := output.CompressFile(*outputFile, billingPeriod)
+			if err != nil {
+				log.Fatalf("Error compressing Excel: %v", err)
+			}
+			*outputFile = archivePath
+			fmt.Printf("  ✓ Compressed to %s\n", archivePath)
+		}
+
+		if *outputGraph != "" {
+			if err := writeGraph(*outputGraph, analysis.BuildDependencyGraph(allBillingRecords)); err != nil {
+				log.Fatalf("Error writing dependency graph: %v", err)
+			}
+			fmt.Printf("  ✓ Dependency graph written to %s\n", *outputGraph)
+		}
+
+		if *lineageFile != "" {
+			if err := output.WriteLineage(*lineageFile, aggregated); err != nil {
+				log.Fatalf("Error writing lineage: %v", err)
+			}
+			fmt.Printf("  ✓ Lineage written to %s\n", *lineageFile)
+		}
+
+		if *githubPRComment {
+			prev := make([]models.AggregatedOutput, 0)
+			if *prCommentBaseline != "" {
+				if prev, err = output.ReadJSON(*prCommentBaseline); err != nil {
+					log.Fatalf("Error loading PR comment baseline: %v", err)
+				}
+			}
+			if err := writeStepSummary(output.WritePRComment(prev, aggregated, billingPeriod)); err != nil {
+				log.Fatalf("Error writing PR comment: %v", err)
+			}
+		}
+
+		if *outputMetadata {
+			meta := output.RunMetadata{
+				ToolVersion:       version,
+				RunTimestamp:      time.Now().UTC(),
+				BillingPeriod:     billingPeriod,
+				RecordsByProvider: make(map[string]int),
+				Warnings:          warnings,
+			}
+			for provider, records := range recordsByProvider {
+				meta.RecordsByProvider[provider] = len(records)
+			}
+			for _, a := range aggregated {
+				meta.TotalSyntheticUnits += a.SyntheticUnits
+			}
+			metaPath, err := output.WriteMetadata(*outputFile, meta)
+			if err != nil {
+				log.Fatalf("Error writing run metadata: %v", err)
+			}
+			fmt.Printf("  ✓ Run metadata written to %s\n", metaPath)
+		}
+
+		// Run user-defined post-processing steps
+		if len(cfg.Workflow.Steps) > 0 {
+			runWorkflow(cfg.Workflow.Steps, aggregated, billingPeriod, *outputFile, *jsonPretty)
+		}
 	}
-	fmt.Println("  ✓ Excel file generated successfully!")
 
 	// Print examples
 	fmt.Println("\n[Examples]")
@@ -106,6 +648,223 @@ func main() {
 	fmt.Println("\n╔══════════════════════════════════════════════════════════════╗")
 	fmt.Println("║                  Processing Complete!                        ║")
 	fmt.Println("╚══════════════════════════════════════════════════════════════╝")
+
+	if *failOnThreshold && len(violations) > 0 {
+		os.Exit(1)
+	}
+}
+
+// writeStepSummary appends Markdown to the GitHub Actions job summary, or prints it when
+// not running in GitHub Actions
+func writeStepSummary(markdown string) error {
+	path := os.Getenv("GITHUB_STEP_SUMMARY")
+	if path == "" {
+		fmt.Println("\n[GitHub] GITHUB_STEP_SUMMARY is not set; PR comment:")
+		fmt.Print(markdown)
+		return nil
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open step summary: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString(markdown); err != nil {
+		return fmt.Errorf("failed to write step summary: %w", err)
+	}
+	fmt.Printf("  ✓ PR comment written to %s\n", path)
+	return nil
+}
+
+// printTrace prints how each asset type's synthetic units were calculated
+func printTrace(aggregated []models.AggregatedOutput, records []models.BillingRecord, rules config.SyntheticUnitsConfig) {
+	fmt.Println("\n=== Calculation Trace ===")
+	byType := billing.SplitByType(records)
+	for _, a := range aggregated {
+		hours := 0.0
+		for _, record := range byType[a.AssetType] {
+			hours += record.InstanceHours
+		}
+
+		fmt.Printf("%s\n", a.AssetType)
+		fmt.Printf("  billed instance-hours: %.2f over %d record(s)\n", hours, len(byType[a.AssetType]))
+		_, steps := assets.ComputeWithTrace(a.AssetType, a.AvgInstancesPerHour, rules)
+		for _, step := range steps {
+			fmt.Printf("  %s\n", step)
+		}
+	}
+}
+
+// writeCombinedExcel writes the main Excel report, split per provider when more than one has
+// data, and returns the rows written to each asset sheet
+func writeCombinedExcel(outputFile string, aggregated []models.AggregatedOutput, pipeline *cloudcost.Pipeline,
+	extraSheets []output.SheetWriter) map[string][]models.AggregatedOutput {
+	if len(pipeline.RecordsByProvider()) <= 1 {
+		if err := output.WriteExcel(outputFile, aggregated, extraSheets...); err != nil {
+			log.Fatalf("Error writing Excel: %v", err)
+		}
+		return map[string][]models.AggregatedOutput{"Sheet1": aggregated}
+	}
+
+	byProvider := pipeline.ByProvider()
+	if err := output.WriteExcelByProvider(outputFile, aggregated, byProvider, extraSheets...); err != nil {
+		log.Fatalf("Error writing Excel: %v", err)
+	}
+
+	written := map[string][]models.AggregatedOutput{"Summary": aggregated}
+	for provider, rows := range byProvider {
+		written[provider] = rows
+	}
+	return written
+}
+
+// sortedSheetKeys returns the sheet names of a per-sheet output map in order
+func sortedSheetKeys(m map[string][]models.AggregatedOutput) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// periodFilename inserts a billing period before the output file's extension
+func periodFilename(outputFile, period string) string {
+	ext := filepath.Ext(outputFile)
+	return fmt.Sprintf("%s-%s%s", strings.TrimSuffix(outputFile, ext), period, ext)
+}
+
+// getRecordKeys returns the keys of a record map (by period, provider, ...) in order
+func getRecordKeys(m map[string][]models.BillingRecord) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// runComparePeriods aggregates each billing period separately and writes a month-over-month report
+func runComparePeriods(pipeline *cloudcost.Pipeline, outputFile string, dryRun bool) {
+	fmt.Println("\n[Processing] Normalizing billing metrics per period...")
+	byPeriod := pipeline.ByPeriod()
+	fmt.Printf("  ✓ Billing periods found: %d\n", len(byPeriod))
+
+	output.PrintMultiPeriodSummary(byPeriod)
+
+	if dryRun {
+		fmt.Printf("\n[Dry Run] Would write Excel file: %s\n", outputFile)
+		return
+	}
+
+	fmt.Printf("\n[Output] Generating Excel file: %s\n", outputFile)
+	if err := output.WriteExcelMultiPeriod(outputFile, byPeriod); err != nil {
+		log.Fatalf("Error writing Excel: %v", err)
+	}
+	fmt.Println("  ✓ Excel file generated successfully!")
+}
+
+// conversionColumns resolves the -conversion-table selection against the configured unit systems
+func conversionColumns(selection string, table config.ConversionTableConfig) []output.ConversionColumn {
+	names := make([]string, 0)
+	if selection == "all" {
+		for name := range table.Systems {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	} else {
+		for _, name := range strings.Split(selection, ",") {
+			names = append(names, strings.TrimSpace(name))
+		}
+	}
+
+	columns := make([]output.ConversionColumn, 0, len(names))
+	for _, name := range names {
+		multiplier, exists := table.Systems[name]
+		if !exists {
+			log.Printf("Warning: unit system %q not found in conversionTable", name)
+			continue
+		}
+		columns = append(columns, output.ConversionColumn{Name: name, Multiplier: multiplier})
+	}
+	return columns
+}
+
+// runWorkflow executes the configured post-processing steps against the aggregated output
+func runWorkflow(steps []config.WorkflowStep, aggregated []models.AggregatedOutput, period, outputFile string, jsonPretty bool) {
+	fmt.Printf("\n[Workflow] Running %d step(s)...\n", len(steps))
+	handlers := map[string]workflow.StepFunc{
+		"write-excel": func(step config.WorkflowStep) error {
+			return output.WriteExcel(step.Path, aggregated)
+		},
+		"write-json": func(step config.WorkflowStep) error {
+			return output.WriteJSON(step.Path, aggregated, jsonPretty)
+		},
+		"notify-slack": func(step config.WorkflowStep) error {
+			return workflow.NotifySlack(step.WebhookURL, summaryText(period, aggregated))
+		},
+		"archive": func(step config.WorkflowStep) error {
+			files := step.Files
+			if len(files) == 0 {
+				files = []string{outputFile}
+			}
+			return workflow.Archive(step.Path, files)
+		},
+	}
+	if err := workflow.Run(steps, handlers); err != nil {
+		log.Fatalf("Error running workflow: %v", err)
+	}
+	fmt.Println("  ✓ Workflow completed")
+}
+
+// summaryText renders a short plain-text summary for notifications
+func summaryText(period string, aggregated []models.AggregatedOutput) string {
+	totalUnits := 0
+	for _, a := range aggregated {
+		totalUnits += a.SyntheticUnits
+	}
+	return fmt.Sprintf("CloudCostCalaCLI report for %s: %d asset types, %d synthetic units", period, len(aggregated), totalUnits)
+}
+
+// lintConfigFile prints deprecated fields found in a config file and returns the exit code
+func lintConfigFile(path string) int {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		log.Fatalf("Error reading config: %v", err)
+	}
+
+	warnings := config.LintConfig(data)
+	if len(warnings) == 0 {
+		fmt.Printf("%s: no deprecated fields found\n", path)
+		return 0
+	}
+	for _, w := range warnings {
+		fmt.Printf("%s: %s\n", path, w)
+	}
+	return 1
+}
+
+// writeGraph writes a dependency graph to a DOT file
+func writeGraph(filename string, graph analysis.DependencyGraph) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create graph file: %w", err)
+	}
+	defer file.Close()
+
+	return graph.WriteDOT(file)
+}
+
+// splitList splits a comma-separated flag value, dropping empty entries
+func splitList(value string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
 }
 
 func getKeys(m map[string]float64) []string {
@@ -0,0 +1,104 @@
+package analysis
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/ozwilder/CloudCostCalaCLI/internal/models"
+)
+
+// DependencyGraph links asset types billed in the same project. Edge weights count the
+// projects in which both types appear.
+type DependencyGraph struct {
+	Nodes []string
+	Edges map[[2]string]int // Keyed by type pair in sorted order
+}
+
+// BuildDependencyGraph groups records by project (account ID, or the parser's default
+// project when there is none) and links every pair of types found together
+func BuildDependencyGraph(records []models.BillingRecord) DependencyGraph {
+	typesByProject := make(map[string]map[string]bool)
+	nodeSet := make(map[string]bool)
+
+	for _, record := range records {
+		project := record.AccountID
+		if project == "" {
+			project = record.Project
+		}
+		if typesByProject[project] == nil {
+			typesByProject[project] = make(map[string]bool)
+		}
+		typesByProject[project][record.ResourceType] = true
+		nodeSet[record.ResourceType] = true
+	}
+
+	graph := DependencyGraph{
+		Nodes: sortedKeys(nodeSet),
+		Edges: make(map[[2]string]int),
+	}
+	for _, types := range typesByProject {
+		sorted := sortedKeys(types)
+		for i := 0; i < len(sorted); i++ {
+			for j := i + 1; j < len(sorted); j++ {
+				graph.Edges[[2]string{sorted[i], sorted[j]}]++
+			}
+		}
+	}
+
+	return graph
+}
+
+// WriteDOT writes the graph in Graphviz DOT format, ready for dot -Tpng. Edge pen widths
+// scale with the co-occurrence count.
+func (g DependencyGraph) WriteDOT(w io.Writer) error {
+	maxWeight := 0
+	for _, weight := range g.Edges {
+		if weight > maxWeight {
+			maxWeight = weight
+		}
+	}
+
+	edges := make([][2]string, 0, len(g.Edges))
+	for edge := range g.Edges {
+		edges = append(edges, edge)
+	}
+	sort.Slice(edges, func(a, b int) bool {
+		if edges[a][0] != edges[b][0] {
+			return edges[a][0] < edges[b][0]
+		}
+		return edges[a][1] < edges[b][1]
+	})
+
+	if _, err := fmt.Fprintln(w, "graph assets {\n  rankdir=LR;\n  node [shape=box];"); err != nil {
+		return fmt.Errorf("failed to write DOT graph: %w", err)
+	}
+	for _, node := range g.Nodes {
+		if _, err := fmt.Fprintf(w, "  %q;\n", node); err != nil {
+			return fmt.Errorf("failed to write DOT graph: %w", err)
+		}
+	}
+	for _, edge := range edges {
+		weight := g.Edges[edge]
+		penWidth := 1 + 4*float64(weight)/float64(maxWeight)
+		if _, err := fmt.Fprintf(w, "  %q -- %q [weight=%d, label=\"%d\", penwidth=%.1f];\n",
+			edge[0], edge[1], weight, weight, penWidth); err != nil {
+			return fmt.Errorf("failed to write DOT graph: %w", err)
+		}
+	}
+	if _, err := fmt.Fprintln(w, "}"); err != nil {
+		return fmt.Errorf("failed to write DOT graph: %w", err)
+	}
+
+	return nil
+}
+
+// sortedKeys returns the keys of a set in sorted order
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,58 @@
+package analysis
+
+import (
+	"sort"
+	"time"
+)
+
+// PeriodAvg is an asset type's average instances per hour in one billing period
+type PeriodAvg struct {
+	Period       string // YYYY-MM
+	AvgInstances float64
+}
+
+// PeriodAvgsByType regroups per-period averages (period -> type -> average) by asset type,
+// each type's periods in chronological order
+func PeriodAvgsByType(avgByPeriod map[string]map[string]float64) map[string][]PeriodAvg {
+	typeData := make(map[string][]PeriodAvg)
+	for period, avgByType := range avgByPeriod {
+		for assetType, avg := range avgByType {
+			typeData[assetType] = append(typeData[assetType], PeriodAvg{Period: period, AvgInstances: avg})
+		}
+	}
+	for _, periods := range typeData {
+		sort.Slice(periods, func(i, j int) bool { return periods[i].Period < periods[j].Period })
+	}
+	return typeData
+}
+
+// YoYGrowthRate compares each asset type's average instances in its latest month with the
+// same month a year earlier and returns the growth rate (0.25 means 25% more). Types without
+// a year-prior month, or with no usage in it, are left out.
+func YoYGrowthRate(typeData map[string][]PeriodAvg) map[string]float64 {
+	growth := make(map[string]float64)
+	for assetType, periods := range typeData {
+		byPeriod := make(map[string]float64, len(periods))
+		latest := ""
+		for _, p := range periods {
+			if _, err := time.Parse("2006-01", p.Period); err != nil {
+				continue
+			}
+			byPeriod[p.Period] += p.AvgInstances
+			if p.Period > latest {
+				latest = p.Period
+			}
+		}
+		if latest == "" {
+			continue
+		}
+
+		month, _ := time.Parse("2006-01", latest)
+		prior, ok := byPeriod[month.AddDate(-1, 0, 0).Format("2006-01")]
+		if !ok || prior == 0 {
+			continue
+		}
+		growth[assetType] = (byPeriod[latest] - prior) / prior
+	}
+	return growth
+}
@@ -0,0 +1,37 @@
+package analysis
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/ozwilder/CloudCostCalaCLI/internal/models"
+)
+
+// CostDeviationLimit is the largest accepted relative difference between billed and
+// synthetic-unit-implied cost before SanityCostCheck warns
+const CostDeviationLimit = 0.20
+
+// SanityCostCheck compares each asset type's billed cost with the cost implied by its
+// synthetic units at unitCost per unit and returns a warning for every type deviating by
+// more than CostDeviationLimit. Types without billed cost (no cost column) or without
+// synthetic units are skipped, as is everything when unitCost is not positive.
+func SanityCostCheck(aggregated []models.AggregatedOutput, unitCost float64) []string {
+	warnings := make([]string, 0)
+	if unitCost <= 0 {
+		return warnings
+	}
+
+	for _, a := range aggregated {
+		implied := float64(a.SyntheticUnits) * unitCost
+		if a.BilledCost == 0 || implied == 0 {
+			continue
+		}
+		deviation := (a.BilledCost - implied) / implied
+		if math.Abs(deviation) > CostDeviationLimit {
+			warnings = append(warnings, fmt.Sprintf("%s billed cost %.2f differs from implied cost %.2f (%d units × %.2f) by %+.0f%%",
+				a.AssetType, a.BilledCost, implied, a.SyntheticUnits, unitCost, deviation*100))
+		}
+	}
+
+	return warnings
+}
@@ -0,0 +1,90 @@
+package analysis
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ozwilder/CloudCostCalaCLI/internal/assets"
+	"github.com/ozwilder/CloudCostCalaCLI/internal/config"
+	"github.com/ozwilder/CloudCostCalaCLI/internal/models"
+)
+
+// Migration moves a fraction of one asset type's instances to another type
+type Migration struct {
+	FromType      string
+	ToType        string
+	Fraction      float64 // Share of FromType instances moved, 0-1
+	InstanceRatio float64 // ToType instances per moved FromType instance (0 means 1)
+}
+
+// SimulateScenario applies migrations in order to a copy of base: each reduces FromType's
+// average instances by Fraction and adds Fraction × InstanceRatio of them to ToType
+// (creating the row when needed). Synthetic units of changed types are recomputed with rules.
+func SimulateScenario(base []models.AggregatedOutput, migrations []Migration, rules config.SyntheticUnitsConfig) []models.AggregatedOutput {
+	scenario := make([]models.AggregatedOutput, len(base))
+	copy(scenario, base)
+
+	index := make(map[string]int, len(scenario))
+	for i, a := range scenario {
+		index[a.AssetType] = i
+	}
+
+	changed := make(map[int]bool)
+	for _, m := range migrations {
+		from, ok := index[m.FromType]
+		if !ok {
+			continue
+		}
+		to, ok := index[m.ToType]
+		if !ok {
+			scenario = append(scenario, models.AggregatedOutput{AssetType: m.ToType})
+			to = len(scenario) - 1
+			index[m.ToType] = to
+		}
+
+		ratio := m.InstanceRatio
+		if ratio == 0 {
+			ratio = 1
+		}
+		moved := scenario[from].AvgInstancesPerHour * m.Fraction
+		scenario[from].AvgInstancesPerHour -= moved
+		scenario[to].AvgInstancesPerHour += moved * ratio
+		changed[from], changed[to] = true, true
+	}
+
+	for i := range changed {
+		scenario[i].SyntheticUnits = assets.ConvertToSyntheticUnits(scenario[i].AssetType, scenario[i].AvgInstancesPerHour, rules)
+	}
+
+	return scenario
+}
+
+// ParseMigration parses a migration written as FROM:TO:FRACTION[:RATIO], e.g. VM:Container:0.3
+func ParseMigration(s string) (Migration, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) < 3 || len(parts) > 4 {
+		return Migration{}, fmt.Errorf("invalid migration %q: expected FROM:TO:FRACTION[:RATIO]", s)
+	}
+
+	m := Migration{FromType: strings.TrimSpace(parts[0]), ToType: strings.TrimSpace(parts[1])}
+	if m.FromType == "" || m.ToType == "" {
+		return Migration{}, fmt.Errorf("invalid migration %q: asset types must not be empty", s)
+	}
+
+	fraction, err := strconv.ParseFloat(strings.TrimSpace(parts[2]), 64)
+	if err != nil || fraction < 0 || fraction > 1 {
+		return Migration{}, fmt.Errorf("invalid migration %q: fraction must be between 0 and 1", s)
+	}
+	m.Fraction = fraction
+
+	if len(parts) == 4 {
+		ratio, err := strconv.ParseFloat(strings.TrimSpace(parts[3]), 64)
+		if err != nil || ratio <= 0 {
+			return Migration{}, fmt.Errorf("invalid migration %q: ratio must be a positive number", s)
+		}
+		m.InstanceRatio = ratio
+	}
+
+	return m, nil
+}
@@ -0,0 +1,94 @@
+package analysis
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ozwilder/CloudCostCalaCLI/internal/models"
+)
+
+// TagPattern suggests Tag=Value for resources whose ID or service name matches Regex
+type TagPattern struct {
+	Tag   string
+	Value string
+	Regex *regexp.Regexp
+}
+
+// TagSuggestion is a tag suggested for one billed resource
+type TagSuggestion struct {
+	ResourceID  string
+	ServiceName string
+	Tag         string
+	Value       string
+}
+
+// DefaultTagPatterns recognizes common environment names in resource names
+var DefaultTagPatterns = []TagPattern{
+	{Tag: "Environment", Value: "prod", Regex: regexp.MustCompile(`(?i)(^|[-_./])(prod|production|prd)([-_./0-9]|$)`)},
+	{Tag: "Environment", Value: "staging", Regex: regexp.MustCompile(`(?i)(^|[-_./])(staging|stage|stg)([-_./0-9]|$)`)},
+	{Tag: "Environment", Value: "dev", Regex: regexp.MustCompile(`(?i)(^|[-_./])(dev|development)([-_./0-9]|$)`)},
+	{Tag: "Environment", Value: "test", Regex: regexp.MustCompile(`(?i)(^|[-_./])(test|qa)([-_./0-9]|$)`)},
+}
+
+// SuggestTags matches a record's resource ID and service name against patterns and returns
+// the suggested value per tag. The first matching pattern wins for each tag, and tags the
+// record already carries in its metadata are not suggested.
+func SuggestTags(record models.BillingRecord, patterns []TagPattern) map[string]string {
+	suggested := make(map[string]string)
+
+	for _, pattern := range patterns {
+		if _, done := suggested[pattern.Tag]; done || hasTag(record.Metadata, pattern.Tag) {
+			continue
+		}
+		if pattern.Regex.MatchString(record.ResourceID) || pattern.Regex.MatchString(record.ServiceName) {
+			suggested[pattern.Tag] = pattern.Value
+		}
+	}
+
+	return suggested
+}
+
+// SuggestTagsForRecords returns tag suggestions for every distinct resource, sorted by resource and tag
+func SuggestTagsForRecords(records []models.BillingRecord, patterns []TagPattern) []TagSuggestion {
+	suggestions := make([]TagSuggestion, 0)
+	seen := make(map[string]bool)
+
+	for _, record := range records {
+		if record.ResourceID == "" || seen[record.ResourceID] {
+			continue
+		}
+		seen[record.ResourceID] = true
+
+		for tag, value := range SuggestTags(record, patterns) {
+			suggestions = append(suggestions, TagSuggestion{
+				ResourceID:  record.ResourceID,
+				ServiceName: record.ServiceName,
+				Tag:         tag,
+				Value:       value,
+			})
+		}
+	}
+
+	sort.Slice(suggestions, func(a, b int) bool {
+		if suggestions[a].ResourceID != suggestions[b].ResourceID {
+			return suggestions[a].ResourceID < suggestions[b].ResourceID
+		}
+		return suggestions[a].Tag < suggestions[b].Tag
+	})
+
+	return suggestions
+}
+
+// hasTag reports whether metadata already carries tag, either as a plain column or as a
+// provider tag column such as "resourceTags/user:Environment"
+func hasTag(metadata map[string]string, tag string) bool {
+	tag = strings.ToLower(tag)
+	for key, value := range metadata {
+		key = strings.ToLower(key)
+		if value != "" && (key == tag || strings.HasSuffix(key, ":"+tag) || strings.HasSuffix(key, "/"+tag)) {
+			return true
+		}
+	}
+	return false
+}
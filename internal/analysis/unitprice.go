@@ -0,0 +1,71 @@
+package analysis
+
+import (
+	"sort"
+
+	"github.com/ozwilder/CloudCostCalaCLI/internal/models"
+)
+
+// UnitPrice is the billed cost per synthetic unit of an asset type in one region
+type UnitPrice struct {
+	AssetType      string
+	Region         string
+	SyntheticUnits float64 // The type's synthetic units attributed to the region
+	BilledCost     float64
+	CostPerUnit    float64
+}
+
+// ComputeUnitPriceList splits each asset type's synthetic units across regions by their
+// share of the type's billed instance-hours and divides each region's billed cost by its
+// units. Types without billed cost or synthetic units are skipped. The list is sorted by
+// CostPerUnit, most expensive first.
+func ComputeUnitPriceList(aggregated []models.AggregatedOutput, records []models.BillingRecord) []UnitPrice {
+	byType := make(map[string][]models.BillingRecord)
+	for _, record := range records {
+		byType[record.ResourceType] = append(byType[record.ResourceType], record)
+	}
+	prices := make([]UnitPrice, 0)
+
+	for _, a := range aggregated {
+		if a.BilledCost == 0 || a.SyntheticUnits == 0 {
+			continue
+		}
+
+		hours := make(map[string]float64)
+		costs := make(map[string]float64)
+		totalHours := 0.0
+		for _, record := range byType[a.AssetType] {
+			hours[record.Region] += record.InstanceHours
+			costs[record.Region] += record.Cost
+			totalHours += record.InstanceHours
+		}
+		if totalHours == 0 {
+			continue
+		}
+
+		for region, regionHours := range hours {
+			units := float64(a.SyntheticUnits) * regionHours / totalHours
+			if units == 0 || costs[region] == 0 {
+				continue
+			}
+			prices = append(prices, UnitPrice{
+				AssetType:      a.AssetType,
+				Region:         region,
+				SyntheticUnits: units,
+				BilledCost:     costs[region],
+				CostPerUnit:    costs[region] / units,
+			})
+		}
+	}
+
+	sort.Slice(prices, func(i, j int) bool {
+		if prices[i].CostPerUnit != prices[j].CostPerUnit {
+			return prices[i].CostPerUnit > prices[j].CostPerUnit
+		}
+		if prices[i].AssetType != prices[j].AssetType {
+			return prices[i].AssetType < prices[j].AssetType
+		}
+		return prices[i].Region < prices[j].Region
+	})
+	return prices
+}
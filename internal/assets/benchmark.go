@@ -0,0 +1,56 @@
+package assets
+
+import (
+	"math"
+
+	"github.com/ozwilder/CloudCostCalaCLI/internal/config"
+	"github.com/ozwilder/CloudCostCalaCLI/internal/models"
+)
+
+// ApplyBenchmark sets each row's percentile rank within its type's industry baseline.
+// Types without a baseline keep a rank of zero.
+func ApplyBenchmark(aggregated []models.AggregatedOutput, baselines []config.IndustryBaseline) []models.AggregatedOutput {
+	byType := make(map[string]config.IndustryBaseline, len(baselines))
+	for _, b := range baselines {
+		byType[b.AssetType] = b
+	}
+
+	result := make([]models.AggregatedOutput, len(aggregated))
+	copy(result, aggregated)
+
+	for i := range result {
+		if baseline, exists := byType[result[i].AssetType]; exists {
+			result[i].PercentileRank = PercentileRank(result[i].SyntheticUnits, baseline)
+		}
+	}
+
+	return result
+}
+
+// PercentileRank estimates where units fall (0-100) in an industry distribution described
+// by its median and 95th percentile. Usage is modelled as log-normal, which fits the long
+// right tail of cloud spend; baselines that cannot describe one (P50 <= 0 or P95 <= P50)
+// fall back to linear interpolation through the two points.
+func PercentileRank(units int, baseline config.IndustryBaseline) float64 {
+	if units <= 0 {
+		return 0
+	}
+
+	p50, p95 := float64(baseline.P50Units), float64(baseline.P95Units)
+	x := float64(units)
+
+	if p50 > 0 && p95 > p50 {
+		mu := math.Log(p50)
+		sigma := (math.Log(p95) - mu) / 1.6449 // z-score of the 95th percentile
+		z := (math.Log(x) - mu) / sigma
+		return 50 * (1 + math.Erf(z/math.Sqrt2))
+	}
+
+	if p50 > 0 && x <= p50 {
+		return 50 * x / p50
+	}
+	if p95 > 0 {
+		return math.Min(100, 50+45*(x-p50)/math.Max(p95-p50, 1))
+	}
+	return 100
+}
@@ -0,0 +1,32 @@
+package assets
+
+import (
+	"sort"
+
+	"github.com/ozwilder/CloudCostCalaCLI/internal/models"
+)
+
+// ApplyBudgets sets each asset type's cost performance index (budgeted / actual units)
+// and sorts the rows by CPI ascending so the most over-budget types come first.
+// Types without a budget or without usage have no CPI (zero) and sort last.
+func ApplyBudgets(aggregated []models.AggregatedOutput, budgets map[string]int) []models.AggregatedOutput {
+	result := make([]models.AggregatedOutput, len(aggregated))
+	copy(result, aggregated)
+
+	for i := range result {
+		budget, exists := budgets[result[i].AssetType]
+		if exists && budget > 0 && result[i].SyntheticUnits > 0 {
+			result[i].CPIScore = float64(budget) / float64(result[i].SyntheticUnits)
+		}
+	}
+
+	sort.SliceStable(result, func(a, b int) bool {
+		cpiA, cpiB := result[a].CPIScore, result[b].CPIScore
+		if cpiA == 0 || cpiB == 0 {
+			return cpiB == 0 && cpiA != 0
+		}
+		return cpiA < cpiB
+	})
+
+	return result
+}
@@ -3,22 +3,120 @@ package assets
 import (
 	"fmt"
 	"math"
+	"strconv"
 
 	"github.com/ozwilder/CloudCostCalaCLI/internal/config"
 )
 
+// TraceStep is one step of a synthetic-unit calculation
+type TraceStep struct {
+	Operation  string  // e.g. "multiply by unitsPerInstance"
+	Expression string  // e.g. "1.5 × 5 = 7.5"
+	Value      float64 // Intermediate result after the step
+}
+
+// String formats the step as "operation: expression"
+func (s TraceStep) String() string {
+	return s.Operation + ": " + s.Expression
+}
+
 // ConvertToSyntheticUnits calculates synthetic units from average instances per hour
 func ConvertToSyntheticUnits(assetType string, avgInstancesPerHour float64, rules config.SyntheticUnitsConfig) int {
+	units, _ := ComputeWithTrace(assetType, avgInstancesPerHour, rules)
+	return units
+}
+
+// ComputeWithTrace calculates synthetic units like ConvertToSyntheticUnits and also returns
+// every step of the calculation, from the average instances to the rounded units
+func ComputeWithTrace(assetType string, avgInstancesPerHour float64, rules config.SyntheticUnitsConfig) (int, []TraceStep) {
+	trace := []TraceStep{{
+		Operation:  "average instances per hour",
+		Expression: formatTraceValue(avgInstancesPerHour),
+		Value:      avgInstancesPerHour,
+	}}
+
 	rule, exists := rules.Rules[assetType]
 	if !exists {
-		return 0 // Unknown asset type
+		// Unknown asset type
+		return 0, append(trace, TraceStep{Operation: "no rule for " + assetType, Expression: "0", Value: 0})
+	}
+
+	var units float64
+	if len(rule.Tiers) > 0 {
+		var tierSteps []TraceStep
+		units, tierSteps = tieredUnits(avgInstancesPerHour, rule.Tiers)
+		trace = append(trace, tierSteps...)
+	} else {
+		// Simple formula: instances per hour * units per instance
+		units = avgInstancesPerHour * float64(rule.UnitsPerInstance)
+		trace = append(trace, TraceStep{
+			Operation:  "multiply by unitsPerInstance",
+			Expression: fmt.Sprintf("%s × %d = %s", formatTraceValue(avgInstancesPerHour), rule.UnitsPerInstance, formatTraceValue(units)),
+			Value:      units,
+		})
+	}
+
+	mode := rule.RoundingMode
+	if mode == "" {
+		mode = config.RoundingRound
 	}
+	totalUnits := roundUnits(units, rule.RoundingMode)
+	trace = append(trace, TraceStep{
+		Operation:  mode,
+		Expression: fmt.Sprintf("%s → %d", formatTraceValue(units), totalUnits),
+		Value:      float64(totalUnits),
+	})
 
-	// Simple formula: instances per hour * units per instance
-	unitsPerInstance := rule.UnitsPerInstance
-	totalUnits := int(math.Round(avgInstancesPerHour * float64(unitsPerInstance)))
+	return totalUnits, trace
+}
+
+// formatTraceValue formats an intermediate value with up to four decimals
+func formatTraceValue(v float64) string {
+	return strconv.FormatFloat(math.Round(v*10000)/10000, 'f', -1, 64)
+}
+
+// roundUnits rounds fractional units with the rule's rounding mode, defaulting to round
+func roundUnits(units float64, mode string) int {
+	switch mode {
+	case config.RoundingCeil:
+		return int(math.Ceil(units))
+	case config.RoundingFloor:
+		return int(math.Floor(units))
+	default:
+		return int(math.Round(units))
+	}
+}
+
+// tieredUnits consumes average instances against each tier's UpTo limit in order, returning
+// the units and one trace step per tier used. Instances beyond the last tier's limit are
+// charged at the last tier's rate.
+func tieredUnits(avgInstancesPerHour float64, tiers []config.SyntheticUnitTier) (float64, []TraceStep) {
+	units := 0.0
+	consumed := 0.0
+	trace := make([]TraceStep, 0, len(tiers))
+
+	for i, tier := range tiers {
+		if consumed >= avgInstancesPerHour {
+			break
+		}
+
+		inTier := avgInstancesPerHour - consumed
+		last := i == len(tiers)-1
+		if tier.UpTo > 0 && !last && tier.UpTo-consumed < inTier {
+			inTier = math.Max(tier.UpTo-consumed, 0)
+		}
+
+		units += inTier * float64(tier.UnitsPerInstance)
+		consumed += inTier
+		trace = append(trace, TraceStep{
+			Operation: fmt.Sprintf("tier %d", i+1),
+			Expression: fmt.Sprintf("%s × %d = %s (running total %s)", formatTraceValue(inTier), tier.UnitsPerInstance,
+				formatTraceValue(inTier*float64(tier.UnitsPerInstance)), formatTraceValue(units)),
+			Value: units,
+		})
+	}
 
-	return totalUnits
+	return units, trace
 }
 
 // ConvertMultiple converts multiple asset types to synthetic units
@@ -0,0 +1,62 @@
+package assets
+
+import (
+	"math"
+	"testing"
+
+	"github.com/ozwilder/CloudCostCalaCLI/internal/config"
+)
+
+func TestTieredUnits(t *testing.T) {
+	tests := []struct {
+		name  string
+		tiers []config.SyntheticUnitTier
+		avg   float64
+		want  float64
+	}{
+		{
+			name:  "single tier matches linear",
+			tiers: []config.SyntheticUnitTier{{UnitsPerInstance: 5}},
+			avg:   1.5,
+			want:  7.5,
+		},
+		{
+			name:  "two tiers, average on the boundary",
+			tiers: []config.SyntheticUnitTier{{UpTo: 5, UnitsPerInstance: 5}, {UnitsPerInstance: 3}},
+			avg:   5,
+			want:  25,
+		},
+		{
+			name: "three tiers, average beyond the last limit",
+			tiers: []config.SyntheticUnitTier{
+				{UpTo: 5, UnitsPerInstance: 5},
+				{UpTo: 10, UnitsPerInstance: 3},
+				{UpTo: 15, UnitsPerInstance: 1},
+			},
+			avg:  20,
+			want: 5*5 + 5*3 + 10*1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _ := tieredUnits(tt.avg, tt.tiers)
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("tieredUnits(%v) = %v, want %v", tt.avg, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertToSyntheticUnitsSingleTierMatchesLinear(t *testing.T) {
+	linear := config.SyntheticUnitsConfig{Rules: map[string]config.SyntheticUnitRule{"VM": {UnitsPerInstance: 5}}}
+	tiered := config.SyntheticUnitsConfig{Rules: map[string]config.SyntheticUnitRule{
+		"VM": {Tiers: []config.SyntheticUnitTier{{UnitsPerInstance: 5}}},
+	}}
+
+	for _, avg := range []float64{0, 0.3, 1.5, 12.25} {
+		if l, tr := ConvertToSyntheticUnits("VM", avg, linear), ConvertToSyntheticUnits("VM", avg, tiered); l != tr {
+			t.Errorf("avg %v: linear %d, single tier %d", avg, l, tr)
+		}
+	}
+}
@@ -1,20 +1,28 @@
 package assets
 
 import (
+	"github.com/ozwilder/CloudCostCalaCLI/internal/billing"
 	"github.com/ozwilder/CloudCostCalaCLI/internal/config"
 	"github.com/ozwilder/CloudCostCalaCLI/internal/models"
 )
 
 // EnrichAssets merges current inventory with billing data
-func EnrichAssets(assets []models.Asset, avgInstancesByType map[string]float64,
+func EnrichAssets(assets []models.Asset, records []models.BillingRecord, avgInstancesByType map[string]float64,
 	rules config.SyntheticUnitsConfig) []models.EnrichedAsset {
 
 	// Group current assets by type
 	assetsByType := make(map[string]int)
+	deploymentsByType := make(map[string]int)
 	for _, asset := range assets {
 		assetsByType[asset.Type]++
+		deploymentsByType[asset.Type] += asset.DeploymentCount
 	}
 
+	// Collect billed resource IDs per type, in order of first appearance
+	resourceIDsByType := collectResourceIDs(records)
+	sudByType := gcpSUDByType(records)
+	costsByType := billing.CostByType(records)
+
 	// Merge and create enriched assets
 	enriched := make([]models.EnrichedAsset, 0)
 	allTypes := mergeKeysStr(assetsByType, avgInstancesByType)
@@ -24,11 +32,27 @@ func EnrichAssets(assets []models.Asset, avgInstancesByType map[string]float64,
 		avgInstances := avgInstancesByType[assetType]
 		hasEphemeral := avgInstances > 0 && currentCount == 0
 
+		// Billed resources are ephemeral when the type is absent from inventory
+		var ephemeralIDs []string
+		if currentCount == 0 {
+			ephemeralIDs = resourceIDsByType[assetType]
+		}
+
+		var costPerDeployment float64
+		if deployments := deploymentsByType[assetType]; deployments > 0 {
+			costPerDeployment = costsByType[assetType] / float64(deployments)
+		}
+
 		enriched = append(enriched, models.EnrichedAsset{
 			AssetType:             assetType,
 			CurrentlyDeployed:     currentCount,
 			AverageInstancesPerHr: avgInstances,
 			HasEphemeralUsage:     hasEphemeral,
+			EphemeralResourceIDs:  ephemeralIDs,
+			DeploymentCount:       deploymentsByType[assetType],
+			TotalCost:             costsByType[assetType],
+			CostPerDeployment:     costPerDeployment,
+			SUDDiscount:           sudByType[assetType],
 			CalculatedUnits:       ConvertToSyntheticUnits(assetType, avgInstances, rules),
 		})
 	}
@@ -41,23 +65,63 @@ func AggregateForOutput(enriched []models.EnrichedAsset) []models.AggregatedOutp
 	output := make([]models.AggregatedOutput, len(enriched))
 
 	for i, e := range enriched {
-		ephemeralCount := 0
-		if e.HasEphemeralUsage {
-			ephemeralCount = 1 // Simplified: at least 1 ephemeral
-		}
-
 		output[i] = models.AggregatedOutput{
 			AssetType:           e.AssetType,
 			CurrentCount:        e.CurrentlyDeployed,
-			EphemeralCount:      ephemeralCount,
+			EphemeralCount:      len(e.EphemeralResourceIDs),
 			AvgInstancesPerHour: e.AverageInstancesPerHr,
 			SyntheticUnits:      e.CalculatedUnits,
+			DeploymentCount:     e.DeploymentCount,
+			CostPerDeployment:   e.CostPerDeployment,
+			BilledCost:          e.TotalCost,
 		}
 	}
 
 	return output
 }
 
+// gcpSUDByType returns the instance-hour weighted sustained-use discount of GCP records per type
+func gcpSUDByType(records []models.BillingRecord) map[string]float64 {
+	discounted := make(map[string]float64)
+	hours := make(map[string]float64)
+
+	for _, record := range records {
+		if record.Provider != "GCP" || record.InstanceHours <= 0 {
+			continue
+		}
+		discounted[record.ResourceType] += billing.ComputeGCPSUD(record) * record.InstanceHours
+		hours[record.ResourceType] += record.InstanceHours
+	}
+
+	result := make(map[string]float64, len(hours))
+	for resourceType, total := range hours {
+		result[resourceType] = discounted[resourceType] / total
+	}
+	return result
+}
+
+// collectResourceIDs returns the distinct resource IDs with non-zero usage, grouped by type
+func collectResourceIDs(records []models.BillingRecord) map[string][]string {
+	seen := make(map[string]map[string]bool)
+	result := make(map[string][]string)
+
+	for _, record := range records {
+		if record.InstanceHours <= 0 || record.ResourceID == "" {
+			continue
+		}
+		if seen[record.ResourceType] == nil {
+			seen[record.ResourceType] = make(map[string]bool)
+		}
+		if seen[record.ResourceType][record.ResourceID] {
+			continue
+		}
+		seen[record.ResourceType][record.ResourceID] = true
+		result[record.ResourceType] = append(result[record.ResourceType], record.ResourceID)
+	}
+
+	return result
+}
+
 // mergeKeys returns unique keys from two maps
 func mergeKeys(m1, m2 map[string]interface{}) []string {
 	keys := make(map[string]bool)
@@ -0,0 +1,74 @@
+package assets
+
+import (
+	"testing"
+
+	"github.com/ozwilder/CloudCostCalaCLI/internal/billing"
+	"github.com/ozwilder/CloudCostCalaCLI/internal/config"
+	"github.com/ozwilder/CloudCostCalaCLI/internal/models"
+)
+
+func TestEphemeralCountCountsDistinctResourceIDs(t *testing.T) {
+	rules := config.SyntheticUnitsConfig{Rules: map[string]config.SyntheticUnitRule{"Function": {UnitsPerInstance: 1}}}
+	inventory := []models.Asset{{ID: "i-1", Type: "VM"}}
+
+	records := make([]models.BillingRecord, 0)
+	for _, id := range []string{"lambda-a", "lambda-b", "lambda-c", "lambda-d", "lambda-e", "lambda-a"} {
+		records = append(records, models.BillingRecord{ResourceType: "Function", ResourceID: id, InstanceHours: 10})
+	}
+
+	enriched := EnrichAssets(inventory, records, map[string]float64{"Function": 0.1}, rules)
+	for _, row := range AggregateForOutput(enriched) {
+		if row.AssetType != "Function" {
+			continue
+		}
+		if row.EphemeralCount != 5 {
+			t.Errorf("EphemeralCount = %d, want 5", row.EphemeralCount)
+		}
+		return
+	}
+	t.Fatal("no Function row in the aggregated output")
+}
+
+func TestGCPSUDByTypeIgnoresProject(t *testing.T) {
+	records := []models.BillingRecord{
+		{Provider: "GCP", ResourceType: "VM", InstanceHours: 700, TimePeriod: "2024-01", Project: billing.MaskValue("gcp-default")},
+		{Provider: "AWS", ResourceType: "VM", InstanceHours: 700, TimePeriod: "2024-01", Project: "gcp-lookalike"},
+	}
+
+	sud := gcpSUDByType(records)
+	if sud["VM"] != 0.30 {
+		t.Errorf("SUD[VM] = %v, want 0.30 from the GCP record alone", sud["VM"])
+	}
+}
+
+func TestEnrichAssetsCostPerDeployment(t *testing.T) {
+	inventory := []models.Asset{
+		{ID: "vm-1", Type: "VM", DeploymentCount: 3},
+		{ID: "vm-2", Type: "VM", DeploymentCount: 1},
+		{ID: "db-1", Type: "Database"},
+	}
+	records := []models.BillingRecord{
+		{ResourceType: "VM", ResourceID: "vm-1", Cost: 60},
+		{ResourceType: "VM", ResourceID: "vm-2", Cost: 40},
+		{ResourceType: "Database", ResourceID: "db-1", Cost: 50},
+	}
+	avg := map[string]float64{"VM": 2, "Database": 1}
+
+	rows := AggregateForOutput(EnrichAssets(inventory, records, avg, config.SyntheticUnitsConfig{}))
+	want := map[string]struct {
+		deployments int
+		billed      float64
+		perDeploy   float64
+	}{
+		"VM":       {4, 100, 25},
+		"Database": {0, 50, 0},
+	}
+	for _, row := range rows {
+		w := want[row.AssetType]
+		if row.DeploymentCount != w.deployments || row.BilledCost != w.billed || row.CostPerDeployment != w.perDeploy {
+			t.Errorf("%s: deployments=%d billed=%v cost/deployment=%v, want %d, %v, %v", row.AssetType,
+				row.DeploymentCount, row.BilledCost, row.CostPerDeployment, w.deployments, w.billed, w.perDeploy)
+		}
+	}
+}
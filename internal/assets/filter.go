@@ -0,0 +1,87 @@
+package assets
+
+import (
+	"log"
+	"strings"
+
+	"github.com/ozwilder/CloudCostCalaCLI/internal/billing"
+	"github.com/ozwilder/CloudCostCalaCLI/internal/config"
+	"github.com/ozwilder/CloudCostCalaCLI/internal/models"
+)
+
+// FilterEphemeral keeps only asset types billed but absent from the current inventory
+func FilterEphemeral(enriched []models.EnrichedAsset) []models.EnrichedAsset {
+	filtered := make([]models.EnrichedAsset, 0)
+	for _, e := range enriched {
+		if e.HasEphemeralUsage && e.CurrentlyDeployed == 0 {
+			filtered = append(filtered, e)
+		}
+	}
+	return filtered
+}
+
+// FilterAggregated keeps only rows whose asset type is in types (case-insensitive).
+// An empty types list keeps every row; a list matching no rows returns an empty slice.
+func FilterAggregated(aggregated []models.AggregatedOutput, types []string) []models.AggregatedOutput {
+	if len(types) == 0 {
+		return aggregated
+	}
+
+	wanted := billing.TypeSet(types)
+	filtered := make([]models.AggregatedOutput, 0)
+	for _, row := range aggregated {
+		if wanted[strings.ToLower(row.AssetType)] {
+			filtered = append(filtered, row)
+		}
+	}
+
+	if len(filtered) == 0 && len(aggregated) > 0 {
+		log.Printf("Warning: type filter %v matched no asset types", types)
+	}
+
+	return filtered
+}
+
+// FilterByCloud keeps inventory assets whose cloud is provider (AWS, Azure, GCP; case-insensitive)
+func FilterByCloud(inventory []models.Asset, provider string) []models.Asset {
+	filtered := make([]models.Asset, 0)
+	for _, asset := range inventory {
+		if strings.EqualFold(asset.Cloud, provider) {
+			filtered = append(filtered, asset)
+		}
+	}
+	return filtered
+}
+
+// SplitInventory assigns inventory assets to the record groups that bill them, matching asset
+// IDs to record resource IDs (masked first when the records' resource IDs are masked). An
+// asset billed in several groups belongs to each; one never billed belongs to none.
+func SplitInventory(inventory []models.Asset, grouped map[string][]models.BillingRecord,
+	masking config.FieldMaskingConfig) map[string][]models.Asset {
+	groupsByID := make(map[string][]string)
+	for group, records := range grouped {
+		for _, record := range records {
+			if ids := groupsByID[record.ResourceID]; len(ids) == 0 || ids[len(ids)-1] != group {
+				groupsByID[record.ResourceID] = append(ids, group)
+			}
+		}
+	}
+
+	split := make(map[string][]models.Asset, len(grouped))
+	for group := range grouped {
+		split[group] = make([]models.Asset, 0)
+	}
+	for _, asset := range inventory {
+		id := asset.ID
+		if masking.MaskResourceID {
+			id = billing.MaskValue(id)
+		}
+		if id == "" {
+			continue
+		}
+		for _, group := range groupsByID[id] {
+			split[group] = append(split[group], asset)
+		}
+	}
+	return split
+}
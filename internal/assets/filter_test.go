@@ -0,0 +1,88 @@
+package assets
+
+import (
+	"testing"
+
+	"github.com/ozwilder/CloudCostCalaCLI/internal/billing"
+	"github.com/ozwilder/CloudCostCalaCLI/internal/config"
+	"github.com/ozwilder/CloudCostCalaCLI/internal/models"
+)
+
+func TestFilterAggregated(t *testing.T) {
+	aggregated := []models.AggregatedOutput{
+		{AssetType: "VM"},
+		{AssetType: "Database"},
+		{AssetType: "Storage"},
+	}
+
+	tests := []struct {
+		name  string
+		types []string
+		want  []string
+	}{
+		{"exact match", []string{"VM", "Storage"}, []string{"VM", "Storage"}},
+		{"partial match, case-insensitive", []string{"DATABASE", "Function"}, []string{"Database"}},
+		{"no match", []string{"Container"}, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FilterAggregated(aggregated, tt.types)
+			if got == nil {
+				t.Fatal("got nil, want an empty or filtered slice")
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d rows, want %d", len(got), len(tt.want))
+			}
+			for i, assetType := range tt.want {
+				if got[i].AssetType != assetType {
+					t.Errorf("row %d = %s, want %s", i, got[i].AssetType, assetType)
+				}
+			}
+		})
+	}
+}
+
+func TestFilterByCloud(t *testing.T) {
+	inventory := []models.Asset{{ID: "a", Cloud: "AWS"}, {ID: "b", Cloud: "azure"}, {ID: "c"}}
+	if got := FilterByCloud(inventory, "Azure"); len(got) != 1 || got[0].ID != "b" {
+		t.Errorf("FilterByCloud(Azure) = %v, want asset b", got)
+	}
+	if got := FilterByCloud(inventory, "GCP"); got == nil || len(got) != 0 {
+		t.Errorf("FilterByCloud(GCP) = %v, want an empty slice", got)
+	}
+}
+
+func TestSplitInventory(t *testing.T) {
+	inventory := []models.Asset{{ID: "i-1"}, {ID: "i-2"}, {ID: "i-3"}}
+	grouped := map[string][]models.BillingRecord{
+		"2024-01": {{ResourceID: "i-1"}, {ResourceID: "i-1"}, {ResourceID: "i-2"}},
+		"2024-02": {{ResourceID: "i-2"}},
+		"2024-03": {{ResourceID: "i-9"}},
+	}
+
+	split := SplitInventory(inventory, grouped, config.FieldMaskingConfig{})
+	want := map[string][]string{"2024-01": {"i-1", "i-2"}, "2024-02": {"i-2"}, "2024-03": {}}
+	for group, ids := range want {
+		assets, exists := split[group]
+		if !exists {
+			t.Fatalf("group %s missing", group)
+		}
+		if len(assets) != len(ids) {
+			t.Fatalf("group %s has %d assets, want %d", group, len(assets), len(ids))
+		}
+		for i, id := range ids {
+			if assets[i].ID != id {
+				t.Errorf("group %s asset %d = %s, want %s", group, i, assets[i].ID, id)
+			}
+		}
+	}
+}
+
+func TestSplitInventoryMasked(t *testing.T) {
+	grouped := map[string][]models.BillingRecord{"core": {{ResourceID: billing.MaskValue("i-1")}}}
+	split := SplitInventory([]models.Asset{{ID: "i-1"}}, grouped, config.FieldMaskingConfig{MaskResourceID: true})
+	if len(split["core"]) != 1 {
+		t.Errorf("got %v, want the asset matched by its masked ID", split)
+	}
+}
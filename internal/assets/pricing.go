@@ -0,0 +1,32 @@
+package assets
+
+import (
+	"github.com/ozwilder/CloudCostCalaCLI/internal/config"
+	"github.com/ozwilder/CloudCostCalaCLI/internal/models"
+)
+
+// DefaultCurrency is used when pricing is configured without a currency
+const DefaultCurrency = "USD"
+
+// ApplyPricing sets each row's estimated cost from its synthetic units. It leaves rows
+// unchanged when no cost per unit is configured.
+func ApplyPricing(aggregated []models.AggregatedOutput, pricing config.PricingConfig) []models.AggregatedOutput {
+	result := make([]models.AggregatedOutput, len(aggregated))
+	copy(result, aggregated)
+
+	if pricing.CostPerSyntheticUnit == 0 {
+		return result
+	}
+
+	currency := pricing.Currency
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+
+	for i := range result {
+		result[i].EstimatedCost = float64(result[i].SyntheticUnits) * pricing.CostPerSyntheticUnit
+		result[i].Currency = currency
+	}
+
+	return result
+}
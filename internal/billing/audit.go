@@ -0,0 +1,76 @@
+package billing
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Reasons recorded for discarded billing rows
+const (
+	ReasonMissingColumns = "row has fewer columns than the header"
+	ReasonUndecodable    = "Kafka message could not be decoded"
+)
+
+// AuditEntry records one billing row discarded while parsing
+type AuditEntry struct {
+	RowIndex int      `json:"row_index"` // CSV row index, the header being row 0, or Kafka message offset
+	Provider string   `json:"provider"`
+	Reason   string   `json:"reason"`
+	RawRow   []string `json:"raw_row"`
+}
+
+// AuditLog collects discarded billing rows. A nil *AuditLog discards nothing.
+type AuditLog struct {
+	Entries []AuditEntry `json:"entries"`
+}
+
+// Add records a discarded row
+func (a *AuditLog) Add(entry AuditEntry) {
+	if a == nil {
+		return
+	}
+	a.Entries = append(a.Entries, entry)
+}
+
+// CountByReason returns the number of discarded rows per reason
+func (a *AuditLog) CountByReason() map[string]int {
+	counts := make(map[string]int)
+	if a == nil {
+		return counts
+	}
+	for _, entry := range a.Entries {
+		counts[entry.Reason]++
+	}
+	return counts
+}
+
+// WriteJSON writes the audit log to a JSON file
+func (a *AuditLog) WriteJSON(filename string) error {
+	entries := make([]AuditEntry, 0)
+	if a != nil {
+		entries = append(entries, a.Entries...)
+	}
+
+	data, err := json.MarshalIndent(AuditLog{Entries: entries}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode audit log: %w", err)
+	}
+	if err := os.WriteFile(filename, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+
+	return nil
+}
+
+// CountByProvider returns the number of discarded rows per provider
+func (a *AuditLog) CountByProvider() map[string]int {
+	counts := make(map[string]int)
+	if a == nil {
+		return counts
+	}
+	for _, entry := range a.Entries {
+		counts[entry.Provider]++
+	}
+	return counts
+}
@@ -0,0 +1,301 @@
+package billing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ozwilder/CloudCostCalaCLI/internal/models"
+)
+
+// Azure endpoints used by FetchAzureMonitorCPUHours
+var (
+	azureLoginURL      = "https://login.microsoftonline.com"
+	azureManagementURL = "https://management.azure.com"
+)
+
+// azureHTTPClient is shared by the Azure Monitor requests
+var azureHTTPClient = &http.Client{Timeout: 60 * time.Second}
+
+// FetchAzureMonitorCPUHours queries Azure Monitor for the hourly average CPU utilization of
+// every VM in a subscription over period (YYYY-MM or YYYY-MM-DD/YYYY-MM-DD) and returns one
+// VM record per machine. InstanceHours is the effective instance-hours: the sum of hourly
+// CPU utilization fractions, so a VM at 50% for 10 hours counts 5. The records are meant
+// for cross-checking billed instance-hours, not for replacing them. Every API request waits
+// on limiter (nil for no limit), see billing.azure.apiRequestsPerSecond.
+func FetchAzureMonitorCPUHours(ctx context.Context, subscriptionID, tenantID, clientID, clientSecret, period string,
+	limiter *RateLimiter) ([]models.BillingRecord, error) {
+	start, end, err := periodBounds(period)
+	if err != nil {
+		return nil, err
+	}
+
+	client := azureClient{ctx: ctx, limiter: limiter}
+	token, err := client.accessToken(tenantID, clientID, clientSecret)
+	if err != nil {
+		return nil, err
+	}
+	client.token = token
+
+	vms, err := client.listVMs(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	timespan := start.Format(time.RFC3339) + "/" + end.Format(time.RFC3339)
+	records := make([]models.BillingRecord, 0, len(vms))
+	for _, vm := range vms {
+		hours, err := client.cpuHours(vm.ID, timespan)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch CPU metrics for %s: %w", vm.Name, err)
+		}
+
+		records = append(records, models.BillingRecord{
+			Provider:      "Azure",
+			ServiceName:   "Virtual Machines",
+			ResourceType:  "VM",
+			ResourceID:    vm.ID,
+			InstanceHours: hours,
+			TimePeriod:    period,
+			Region:        vm.Location,
+			AccountID:     subscriptionID,
+			Project:       "azure-monitor",
+			Metadata:      map[string]string{"source": "azure-monitor"},
+		})
+	}
+
+	return records, nil
+}
+
+// VMHoursDiscrepancy is a VM whose billed instance-hours and Azure Monitor hours disagree
+type VMHoursDiscrepancy struct {
+	ResourceID     string
+	BilledHours    float64
+	MonitoredHours float64
+	Message        string
+}
+
+// String formats the discrepancy for console output
+func (d VMHoursDiscrepancy) String() string {
+	return fmt.Sprintf("%s: %s (billed %.2f, Azure Monitor %.2f)", d.ResourceID, d.Message, d.BilledHours, d.MonitoredHours)
+}
+
+// CrossCheckAzureVMHours compares billed Azure VM instance-hours with the effective hours
+// from FetchAzureMonitorCPUHours. VMs are matched by the last segment of their resource ID,
+// case-insensitively, since billing exports hold either full ARM IDs or VM names. Effective
+// hours are at most the billed hours, so the check reports VMs billed but unknown to Azure
+// Monitor, VMs running without being billed, and VMs with more effective than billed hours.
+func CrossCheckAzureVMHours(billed, monitored []models.BillingRecord) []VMHoursDiscrepancy {
+	billedHours := make(map[string]float64)
+	billedIDs := make(map[string]string)
+	for _, record := range billed {
+		if record.ResourceType != "VM" || !strings.EqualFold(record.Provider, "Azure") {
+			continue
+		}
+		key := vmKey(record.ResourceID)
+		billedHours[key] += record.InstanceHours
+		billedIDs[key] = record.ResourceID
+	}
+
+	monitoredHours := make(map[string]float64)
+	monitoredIDs := make(map[string]string)
+	for _, record := range monitored {
+		key := vmKey(record.ResourceID)
+		monitoredHours[key] += record.InstanceHours
+		monitoredIDs[key] = record.ResourceID
+	}
+
+	discrepancies := make([]VMHoursDiscrepancy, 0)
+	for key, hours := range billedHours {
+		actual, exists := monitoredHours[key]
+		switch {
+		case !exists:
+			discrepancies = append(discrepancies, VMHoursDiscrepancy{ResourceID: billedIDs[key],
+				BilledHours: hours, Message: "billed but not reported by Azure Monitor"})
+		case actual > hours+0.01:
+			discrepancies = append(discrepancies, VMHoursDiscrepancy{ResourceID: billedIDs[key],
+				BilledHours: hours, MonitoredHours: actual, Message: "Azure Monitor reports more hours than billed"})
+		}
+	}
+	for key, actual := range monitoredHours {
+		if _, exists := billedHours[key]; !exists && actual > 0 {
+			discrepancies = append(discrepancies, VMHoursDiscrepancy{ResourceID: monitoredIDs[key],
+				MonitoredHours: actual, Message: "reported by Azure Monitor but not billed"})
+		}
+	}
+
+	sort.Slice(discrepancies, func(i, j int) bool {
+		return discrepancies[i].ResourceID < discrepancies[j].ResourceID
+	})
+	return discrepancies
+}
+
+// vmKey returns the VM name of a resource ID or name, lowercased for matching
+func vmKey(resourceID string) string {
+	return strings.ToLower(path.Base(strings.TrimRight(resourceID, "/")))
+}
+
+// azureVM is the subset of an ARM virtual machine resource used here
+type azureVM struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Location string `json:"location"`
+}
+
+// azureClient sends rate-limited Azure API requests bound to a context
+type azureClient struct {
+	ctx     context.Context
+	limiter *RateLimiter
+	token   string
+}
+
+// accessToken obtains an ARM access token with the client credentials flow
+func (c azureClient) accessToken(tenantID, clientID, clientSecret string) (string, error) {
+	form := url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {clientID},
+		"client_secret": {clientSecret},
+		"scope":         {azureManagementURL + "/.default"},
+	}
+
+	endpoint := fmt.Sprintf("%s/%s/oauth2/v2.0/token", azureLoginURL, url.PathEscape(tenantID))
+	req, err := http.NewRequestWithContext(c.ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to request Azure access token: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to request Azure access token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := decodeAzureResponse(resp, &body); err != nil {
+		return "", fmt.Errorf("failed to obtain Azure access token: %w", err)
+	}
+	return body.AccessToken, nil
+}
+
+// listVMs lists every virtual machine in a subscription, following pagination links
+func (c azureClient) listVMs(subscriptionID string) ([]azureVM, error) {
+	next := fmt.Sprintf("%s/subscriptions/%s/providers/Microsoft.Compute/virtualMachines?api-version=2023-03-01",
+		azureManagementURL, url.PathEscape(subscriptionID))
+
+	vms := make([]azureVM, 0)
+	for next != "" {
+		var page struct {
+			Value    []azureVM `json:"value"`
+			NextLink string    `json:"nextLink"`
+		}
+		if err := c.get(next, &page); err != nil {
+			return nil, fmt.Errorf("failed to list Azure VMs: %w", err)
+		}
+		vms = append(vms, page.Value...)
+		next = page.NextLink
+	}
+
+	return vms, nil
+}
+
+// cpuHours sums a VM's hourly average CPU utilization as fractions of an instance
+func (c azureClient) cpuHours(resourceID, timespan string) (float64, error) {
+	query := url.Values{
+		"metricnames": {"Percentage CPU"},
+		"timespan":    {timespan},
+		"interval":    {"PT1H"},
+		"aggregation": {"Average"},
+		"api-version": {"2018-01-01"},
+	}
+
+	var body struct {
+		Value []struct {
+			Timeseries []struct {
+				Data []struct {
+					Average *float64 `json:"average"`
+				} `json:"data"`
+			} `json:"timeseries"`
+		} `json:"value"`
+	}
+	endpoint := azureManagementURL + resourceID + "/providers/Microsoft.Insights/metrics?" + query.Encode()
+	if err := c.get(endpoint, &body); err != nil {
+		return 0, err
+	}
+
+	hours := 0.0
+	for _, metric := range body.Value {
+		for _, series := range metric.Timeseries {
+			for _, point := range series.Data {
+				if point.Average != nil {
+					hours += *point.Average / 100
+				}
+			}
+		}
+	}
+	return hours, nil
+}
+
+// get performs an authenticated GET and decodes the JSON response into v
+func (c azureClient) get(endpoint string, v interface{}) error {
+	req, err := http.NewRequestWithContext(c.ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return decodeAzureResponse(resp, v)
+}
+
+// do sends a request once the rate limiter allows it
+func (c azureClient) do(req *http.Request) (*http.Response, error) {
+	if err := c.limiter.Wait(c.ctx); err != nil {
+		return nil, err
+	}
+	return azureHTTPClient.Do(req)
+}
+
+// decodeAzureResponse decodes a successful JSON response, or returns the error body
+func decodeAzureResponse(resp *http.Response, v interface{}) error {
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// periodBounds returns the start and exclusive end of a YYYY-MM or YYYY-MM-DD/YYYY-MM-DD period
+func periodBounds(period string) (time.Time, time.Time, error) {
+	if start, end, found := strings.Cut(period, "/"); found {
+		startDate, err := time.Parse("2006-01-02", strings.TrimSpace(start))
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid period %q: %w", period, err)
+		}
+		endDate, err := time.Parse("2006-01-02", strings.TrimSpace(end))
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid period %q: %w", period, err)
+		}
+		return startDate, endDate.AddDate(0, 0, 1), nil
+	}
+
+	month, err := time.Parse("2006-01", period)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid period %q: %w", period, err)
+	}
+	return month, month.AddDate(0, 1, 0), nil
+}
@@ -0,0 +1,192 @@
+package billing
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ozwilder/CloudCostCalaCLI/internal/models"
+)
+
+const testVMPrefix = "/subscriptions/sub-1/resourceGroups/rg/providers/Microsoft.Compute/virtualMachines/"
+
+// azureTestServer serves the token, paginated VM list and metrics endpoints, and points
+// the Azure URLs at itself for the duration of the test
+func azureTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reply := func(v interface{}) {
+			if err := json.NewEncoder(w).Encode(v); err != nil {
+				t.Errorf("encode response: %v", err)
+			}
+		}
+
+		if r.URL.Path == "/tenant-1/oauth2/v2.0/token" {
+			if err := r.ParseForm(); err != nil {
+				t.Errorf("parse token form: %v", err)
+			}
+			if r.Form.Get("client_id") != "client-1" || r.Form.Get("client_secret") != "secret-1" {
+				t.Errorf("token request form = %v, want the client credentials", r.Form)
+			}
+			reply(map[string]string{"access_token": "token-1"})
+			return
+		}
+
+		if got := r.Header.Get("Authorization"); got != "Bearer token-1" {
+			t.Errorf("%s Authorization = %q, want the issued token", r.URL.Path, got)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch {
+		case r.URL.Path == "/subscriptions/sub-1/providers/Microsoft.Compute/virtualMachines" && r.URL.Query().Get("page") == "":
+			reply(map[string]interface{}{
+				"value":    []azureVM{{ID: testVMPrefix + "vm-1", Name: "vm-1", Location: "eastus"}},
+				"nextLink": server.URL + "/subscriptions/sub-1/providers/Microsoft.Compute/virtualMachines?page=2",
+			})
+		case r.URL.Path == "/subscriptions/sub-1/providers/Microsoft.Compute/virtualMachines":
+			reply(map[string]interface{}{
+				"value": []azureVM{{ID: testVMPrefix + "vm-2", Name: "vm-2", Location: "westeurope"}},
+			})
+		case strings.HasSuffix(r.URL.Path, "/providers/Microsoft.Insights/metrics"):
+			if got := r.URL.Query().Get("timespan"); got != "2024-01-01T00:00:00Z/2024-02-01T00:00:00Z" {
+				t.Errorf("metrics timespan = %q, want January 2024", got)
+			}
+			// vm-1 averages 50% then 100% CPU with a gap; vm-2 idles at 10%
+			averages := []interface{}{50.0, 100.0, nil}
+			if strings.HasSuffix(r.URL.Path, "/vm-2/providers/Microsoft.Insights/metrics") {
+				averages = []interface{}{10.0}
+			}
+			data := make([]map[string]interface{}, len(averages))
+			for i, average := range averages {
+				data[i] = map[string]interface{}{"average": average}
+			}
+			reply(map[string]interface{}{
+				"value": []interface{}{map[string]interface{}{
+					"timeseries": []interface{}{map[string]interface{}{"data": data}},
+				}},
+			})
+		default:
+			t.Errorf("unexpected request %s", r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	loginURL, managementURL := azureLoginURL, azureManagementURL
+	azureLoginURL, azureManagementURL = server.URL, server.URL
+	t.Cleanup(func() { azureLoginURL, azureManagementURL = loginURL, managementURL })
+
+	return server
+}
+
+func TestFetchAzureMonitorCPUHours(t *testing.T) {
+	azureTestServer(t)
+
+	records, err := FetchAzureMonitorCPUHours(context.Background(), "sub-1", "tenant-1", "client-1", "secret-1", "2024-01", nil)
+	if err != nil {
+		t.Fatalf("FetchAzureMonitorCPUHours: %v", err)
+	}
+
+	want := map[string]float64{testVMPrefix + "vm-1": 1.5, testVMPrefix + "vm-2": 0.1}
+	if len(records) != len(want) {
+		t.Fatalf("got %d records, want one per VM across both pages: %+v", len(records), records)
+	}
+	for _, record := range records {
+		hours, exists := want[record.ResourceID]
+		if !exists {
+			t.Errorf("unexpected record for %s", record.ResourceID)
+			continue
+		}
+		if math.Abs(record.InstanceHours-hours) > 1e-9 {
+			t.Errorf("%s instance-hours = %v, want %v", record.ResourceID, record.InstanceHours, hours)
+		}
+		if record.Provider != "Azure" || record.ResourceType != "VM" || record.TimePeriod != "2024-01" {
+			t.Errorf("record = %+v, want an Azure VM record for 2024-01", record)
+		}
+	}
+}
+
+func TestFetchAzureMonitorCPUHoursRateLimited(t *testing.T) {
+	server := azureTestServer(t)
+	var mu sync.Mutex
+	var arrivals []time.Time
+	handler := server.Config.Handler
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		arrivals = append(arrivals, time.Now())
+		mu.Unlock()
+		handler.ServeHTTP(w, r)
+	})
+
+	interval := 50 * time.Millisecond
+	_, err := FetchAzureMonitorCPUHours(context.Background(), "sub-1", "tenant-1", "client-1", "secret-1", "2024-01",
+		NewRateLimiter(float64(time.Second/interval)))
+	if err != nil {
+		t.Fatalf("FetchAzureMonitorCPUHours: %v", err)
+	}
+
+	// Token, two VM list pages and two metrics requests
+	if len(arrivals) != 5 {
+		t.Fatalf("got %d requests, want 5", len(arrivals))
+	}
+	for i := 1; i < len(arrivals); i++ {
+		if gap := arrivals[i].Sub(arrivals[i-1]); gap < interval*8/10 {
+			t.Errorf("request %d arrived %v after the previous one, want about %v apart", i, gap, interval)
+		}
+	}
+}
+
+func TestFetchAzureMonitorCPUHoursReportsHTTPErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid client secret", http.StatusUnauthorized)
+	}))
+	defer server.Close()
+	loginURL := azureLoginURL
+	azureLoginURL = server.URL
+	defer func() { azureLoginURL = loginURL }()
+
+	_, err := FetchAzureMonitorCPUHours(context.Background(), "sub-1", "tenant-1", "client-1", "wrong", "2024-01", nil)
+	if err == nil || !strings.Contains(err.Error(), "invalid client secret") {
+		t.Errorf("got error %v, want the token endpoint's error", err)
+	}
+}
+
+func TestCrossCheckAzureVMHours(t *testing.T) {
+	billed := []models.BillingRecord{
+		{Provider: "Azure", ResourceType: "VM", ResourceID: "vm-1", InstanceHours: 744},
+		{Provider: "Azure", ResourceType: "VM", ResourceID: "vm-2", InstanceHours: 10},
+		{Provider: "Azure", ResourceType: "VM", ResourceID: "vm-3", InstanceHours: 100},
+		{Provider: "Azure", ResourceType: "Database", ResourceID: "db-1", InstanceHours: 744},
+		{Provider: "AWS", ResourceType: "VM", ResourceID: "i-1", InstanceHours: 744},
+	}
+	monitored := []models.BillingRecord{
+		{ResourceID: testVMPrefix + "VM-1", InstanceHours: 300},
+		{ResourceID: testVMPrefix + "vm-2", InstanceHours: 12},
+		{ResourceID: testVMPrefix + "vm-4", InstanceHours: 5},
+		{ResourceID: testVMPrefix + "vm-5", InstanceHours: 0},
+	}
+
+	got := CrossCheckAzureVMHours(billed, monitored)
+	want := []struct{ id, message string }{
+		{testVMPrefix + "vm-4", "reported by Azure Monitor but not billed"},
+		{"vm-2", "Azure Monitor reports more hours than billed"},
+		{"vm-3", "billed but not reported by Azure Monitor"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d discrepancies, want %d: %v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		if got[i].ResourceID != w.id || got[i].Message != w.message {
+			t.Errorf("discrepancy %d = %v, want %s: %s", i, got[i], w.id, w.message)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package billing
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDetectCURVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers []string
+		want    int
+		wantErr bool
+	}{
+		{"CUR 1.0", []string{"identity/LineItemId", "bill/BillingEntity", "lineItem/UsageAmount"}, CURVersion1, false},
+		{"CUR 1.0 with BOM and case", []string{"\ufeffBILL/BILLINGENTITY", "lineItem/UsageAmount"}, CURVersion1, false},
+		{"CUR 2.0", []string{"line_item_usage_amount", "split_line_item_split_cost"}, CURVersion2, false},
+		{"generic CSV", []string{"service", "resourceId", "instanceHours", "period", "region"}, CURVersionNone, false},
+		{"both sentinels", []string{"bill/BillingEntity", "split_line_item_split_cost"}, CURVersionNone, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DetectCURVersion(tt.headers)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("DetectCURVersion() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAWSBillingCURVersions(t *testing.T) {
+	tests := []struct {
+		file                                  string
+		serviceName, resourceType, resourceID string
+		period, region, accountID             string
+		hours, cost                           float64
+		start                                 time.Time
+	}{
+		{
+			file: "testdata/aws-cur-v1.csv", serviceName: "AmazonEC2", resourceType: "VM", resourceID: "i-0abc",
+			period: "2024-01", region: "us-east-1", accountID: "111122223333", hours: 720, cost: 52.10,
+			start: time.Date(2024, 1, 5, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			file: "testdata/aws-cur-v2.csv", serviceName: "AmazonEC2", resourceType: "VM", resourceID: "i-0def",
+			period: "2024-02", region: "us-west-2", accountID: "444455556666", hours: 672, cost: 48.00,
+			start: time.Date(2024, 2, 3, 8, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			records, err := ParseBillingFile(tt.file, "aws", ParseOptions{})
+			if err != nil {
+				t.Fatalf("ParseBillingFile: %v", err)
+			}
+			if len(records) != 2 {
+				t.Fatalf("got %d records, want 2", len(records))
+			}
+
+			r := records[0]
+			if r.ServiceName != tt.serviceName || r.ResourceType != tt.resourceType || r.ResourceID != tt.resourceID ||
+				r.TimePeriod != tt.period || r.Region != tt.region || r.AccountID != tt.accountID ||
+				r.InstanceHours != tt.hours || r.Cost != tt.cost || !r.StartTime.Equal(tt.start) {
+				t.Errorf("first record = %+v", r)
+			}
+		})
+	}
+}
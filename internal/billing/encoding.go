@@ -0,0 +1,44 @@
+package billing
+
+import (
+	"bytes"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// encodingSampleSize is how many leading bytes are scanned for UTF-8 validity
+const encodingSampleSize = 64 * 1024
+
+// detectEncoding guesses a billing file's text encoding from a leading sample of it.
+// Files with a UTF-16 BOM are decoded as UTF-16, valid UTF-8 (with or without BOM)
+// as UTF-8, and anything else falls back to Windows-1252, the usual spreadsheet export.
+// complete reports whether the sample holds the whole file.
+func detectEncoding(sample []byte, complete bool) encoding.Encoding {
+	switch {
+	case bytes.HasPrefix(sample, []byte{0xEF, 0xBB, 0xBF}):
+		return unicode.UTF8BOM
+	case bytes.HasPrefix(sample, []byte{0xFF, 0xFE}):
+		return unicode.UTF16(unicode.LittleEndian, unicode.UseBOM)
+	case bytes.HasPrefix(sample, []byte{0xFE, 0xFF}):
+		return unicode.UTF16(unicode.BigEndian, unicode.UseBOM)
+	}
+
+	// A multi-byte rune may be cut at the end of the sample; ignore that tail
+	if !complete {
+		for i := 0; i < utf8.UTFMax-1; i++ {
+			r, size := utf8.DecodeLastRune(sample)
+			if r != utf8.RuneError || size != 1 {
+				break
+			}
+			sample = sample[:len(sample)-1]
+		}
+	}
+	if utf8.Valid(sample) {
+		return unicode.UTF8
+	}
+
+	return charmap.Windows1252
+}
@@ -0,0 +1,70 @@
+package billing
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+)
+
+func TestDetectEncoding(t *testing.T) {
+	tests := []struct {
+		name     string
+		sample   []byte
+		complete bool
+		want     encoding.Encoding
+	}{
+		{"UTF-8 BOM", []byte("\ufeffservice,cost\n"), true, unicode.UTF8BOM},
+		{"UTF-16 LE BOM", []byte{0xFF, 0xFE, 's', 0}, true, unicode.UTF16(unicode.LittleEndian, unicode.UseBOM)},
+		{"UTF-16 BE BOM", []byte{0xFE, 0xFF, 0, 's'}, true, unicode.UTF16(unicode.BigEndian, unicode.UseBOM)},
+		{"plain UTF-8", []byte("region,café\n"), true, unicode.UTF8},
+		{"Windows-1252", []byte("region,caf\xe9\n"), true, charmap.Windows1252},
+		// "é" is cut after its first byte at the end of a partial sample
+		{"truncated UTF-8 sample", []byte("region,caf\xc3"), false, unicode.UTF8},
+		{"truncated byte in complete file", []byte("region,caf\xc3"), true, charmap.Windows1252},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectEncoding(tt.sample, tt.complete); got != tt.want {
+				t.Errorf("detectEncoding() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDetectsEncoding(t *testing.T) {
+	const csv = "service,resourceId,instanceHours,period,region\nEC2,i-café,720,2024-01,us-east-1\n"
+	utf16, err := unicode.UTF16(unicode.LittleEndian, unicode.UseBOM).NewEncoder().String(csv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	windows1252, err := charmap.Windows1252.NewEncoder().String(csv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string]string{
+		"UTF-8 BOM":    "\ufeff" + csv,
+		"UTF-16":       utf16,
+		"Windows-1252": windows1252,
+	}
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "aws.csv")
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			records, err := ParseBillingFile(path, "aws", ParseOptions{DetectEncoding: true})
+			if err != nil {
+				t.Fatalf("ParseBillingFile: %v", err)
+			}
+			if len(records) != 1 || records[0].ResourceID != "i-café" {
+				t.Errorf("got records %+v, want one with ResourceID i-café", records)
+			}
+		})
+	}
+}
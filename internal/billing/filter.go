@@ -0,0 +1,41 @@
+package billing
+
+import (
+	"log"
+	"strings"
+
+	"github.com/ozwilder/CloudCostCalaCLI/internal/models"
+)
+
+// FilterByTypes keeps only records whose resource type is in types (case-insensitive).
+// An empty types list keeps every record; a list matching no records returns an empty slice.
+func FilterByTypes(records []models.BillingRecord, types []string) []models.BillingRecord {
+	if len(types) == 0 {
+		return records
+	}
+
+	wanted := TypeSet(types)
+	filtered := make([]models.BillingRecord, 0)
+	for _, record := range records {
+		if wanted[strings.ToLower(record.ResourceType)] {
+			filtered = append(filtered, record)
+		}
+	}
+
+	if len(filtered) == 0 && len(records) > 0 {
+		log.Printf("Warning: type filter %v matched no billing records", types)
+	}
+
+	return filtered
+}
+
+// TypeSet builds a lower-cased lookup set from a list of asset types
+func TypeSet(types []string) map[string]bool {
+	set := make(map[string]bool, len(types))
+	for _, t := range types {
+		if t = strings.TrimSpace(t); t != "" {
+			set[strings.ToLower(t)] = true
+		}
+	}
+	return set
+}
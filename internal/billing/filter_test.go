@@ -0,0 +1,40 @@
+package billing
+
+import (
+	"testing"
+
+	"github.com/ozwilder/CloudCostCalaCLI/internal/models"
+)
+
+func TestFilterByTypes(t *testing.T) {
+	records := []models.BillingRecord{
+		{ResourceType: "VM", ResourceID: "i-1"},
+		{ResourceType: "Database", ResourceID: "db-1"},
+		{ResourceType: "Storage", ResourceID: "bucket-1"},
+		{ResourceType: "VM", ResourceID: "i-2"},
+	}
+
+	tests := []struct {
+		name  string
+		types []string
+		want  []string
+	}{
+		{"exact match", []string{"VM", "Database"}, []string{"i-1", "db-1", "i-2"}},
+		{"partial match, case-insensitive", []string{"vm", " Function "}, []string{"i-1", "i-2"}},
+		{"no match", []string{"Container"}, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FilterByTypes(records, tt.types)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d records, want %d", len(got), len(tt.want))
+			}
+			for i, id := range tt.want {
+				if got[i].ResourceID != id {
+					t.Errorf("record %d = %s, want %s", i, got[i].ResourceID, id)
+				}
+			}
+		})
+	}
+}
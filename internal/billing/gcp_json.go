@@ -0,0 +1,146 @@
+package billing
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ozwilder/CloudCostCalaCLI/internal/models"
+)
+
+// gcpBillingRow is one row of a BigQuery billing export downloaded as JSON
+type gcpBillingRow struct {
+	Service struct {
+		Description string `json:"description"`
+	} `json:"service"`
+	Usage struct {
+		Amount              float64 `json:"amount"`
+		Unit                string  `json:"unit"`
+		PricingUnitQuantity float64 `json:"pricing_unit_quantity"`
+	} `json:"usage"`
+	Resource struct {
+		Name string `json:"name"`
+	} `json:"resource"`
+	Location struct {
+		Region string `json:"region"`
+	} `json:"location"`
+	Project struct {
+		ID string `json:"id"`
+	} `json:"project"`
+	UsageStartTime string  `json:"usage_start_time"`
+	Cost           float64 `json:"cost"`
+	Invoice        struct {
+		Month string `json:"month"` // YYYYMM
+	} `json:"invoice"`
+}
+
+// streamGCPBillingJSON handles GCP BigQuery billing exports saved as a JSON array
+// or as newline-delimited JSON objects. The file is decoded whole before any record
+// is passed to emit.
+func streamGCPBillingJSON(filePath string, opts ParseOptions, emit func(models.BillingRecord) error) error {
+	file, err := openBillingFile(filePath, opts.MaxFileSizeMB)
+	if err != nil {
+		return fmt.Errorf("failed to open GCP billing file: %w", err)
+	}
+	defer file.Close()
+
+	rows, err := decodeGCPBillingRows(bufio.NewReader(file))
+	if err != nil {
+		return fmt.Errorf("failed to read GCP billing JSON: %w", err)
+	}
+
+	for i, row := range rows {
+		instanceHours := row.Usage.Amount
+		if row.Usage.Unit != "h" && row.Usage.PricingUnitQuantity > 0 {
+			instanceHours = row.Usage.Amount / row.Usage.PricingUnitQuantity
+		}
+
+		resourceID := row.Resource.Name
+		if opts.Masking.MaskResourceID {
+			resourceID = MaskValue(resourceID)
+		}
+
+		accountID := row.Project.ID
+		if accountID == "" {
+			accountID = opts.AccountID
+		}
+
+		if err := emit(models.BillingRecord{
+			Provider:      "GCP",
+			ServiceName:   row.Service.Description,
+			ResourceType:  mapGCPServiceToType(row.Service.Description),
+			ResourceID:    resourceID,
+			SourceRow:     i + 1,
+			InstanceHours: instanceHours,
+			Cost:          row.Cost,
+			StartTime:     parseStartTime(row.UsageStartTime),
+			TimePeriod:    invoiceMonthToPeriod(row.Invoice.Month),
+			Region:        row.Location.Region,
+			AccountID:     accountID,
+			Project:       "gcp-default",
+			Metadata:      make(map[string]string),
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// decodeGCPBillingRows decodes either a JSON array of rows or a stream of row objects
+func decodeGCPBillingRows(r *bufio.Reader) ([]gcpBillingRow, error) {
+	decoder := json.NewDecoder(r)
+	rows := make([]gcpBillingRow, 0)
+
+	first, err := firstNonSpace(r)
+	if err == io.EOF {
+		return rows, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if first == '[' {
+		if err := decoder.Decode(&rows); err != nil {
+			return nil, err
+		}
+		return rows, nil
+	}
+
+	for {
+		var row gcpBillingRow
+		if err := decoder.Decode(&row); err == io.EOF {
+			return rows, nil
+		} else if err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+}
+
+// firstNonSpace skips leading whitespace and any UTF-8 byte order mark, returning the
+// first remaining byte without consuming it
+func firstNonSpace(r *bufio.Reader) (byte, error) {
+	if bom, err := r.Peek(3); err == nil && string(bom) == "\ufeff" {
+		r.Discard(3)
+	}
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		if !strings.ContainsRune(" \t\r\n", rune(b)) {
+			return b, r.UnreadByte()
+		}
+	}
+}
+
+// invoiceMonthToPeriod converts a BigQuery invoice month (YYYYMM) to YYYY-MM
+func invoiceMonthToPeriod(month string) string {
+	if len(month) == 6 {
+		return month[:4] + "-" + month[4:]
+	}
+	return month
+}
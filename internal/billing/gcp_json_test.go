@@ -0,0 +1,37 @@
+package billing
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseGCPBillingJSON(t *testing.T) {
+	records, err := ParseBillingFile("testdata/gcp-bigquery.json", "gcp", ParseOptions{GCPFormat: "json", AccountID: "fallback"})
+	if err != nil {
+		t.Fatalf("ParseBillingFile: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("got %d records, want 3", len(records))
+	}
+
+	tests := []struct {
+		resourceType, resourceID, region, period, accountID string
+		hours, cost                                         float64
+	}{
+		{"VM", "projects/demo/instances/web-1", "us-central1", "2024-01", "demo-project", 720, 52.5},
+		{"Database", "projects/demo/instances/db-1", "europe-west1", "2024-01", "demo-project", 360, 80},
+		{"Function", "projects/demo/functions/resize", "us-east1", "2024-02", "fallback", 12, 0},
+	}
+	for i, want := range tests {
+		r := records[i]
+		if r.ResourceType != want.resourceType || r.ResourceID != want.resourceID || r.Region != want.region ||
+			r.TimePeriod != want.period || r.AccountID != want.accountID || r.InstanceHours != want.hours ||
+			r.Cost != want.cost || r.Provider != "GCP" || r.SourceRow != i+1 {
+			t.Errorf("record %d = %+v, want %+v", i, r, want)
+		}
+	}
+
+	if want := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC); !records[0].StartTime.Equal(want) {
+		t.Errorf("start time = %v, want %v", records[0].StartTime, want)
+	}
+}
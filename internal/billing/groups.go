@@ -0,0 +1,107 @@
+package billing
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ozwilder/CloudCostCalaCLI/internal/config"
+	"github.com/ozwilder/CloudCostCalaCLI/internal/models"
+)
+
+// UngroupedVPC is the group name for records without a mapped VPC/VNet
+const UngroupedVPC = "Ungrouped"
+
+// vpcMetadataKeys are the billing columns that may carry a VPC or VNet ID, in the order
+// they are checked when a record has several
+var vpcMetadataKeys = []string{"vpcid", "vpc_id", "vpc", "vnetid", "vnet_id", "vnet", "networkid"}
+
+// GroupByVPC partitions records by the network group their VPC/VNet ID maps to
+func GroupByVPC(records []models.BillingRecord, groups config.VPCGroupConfig) map[string][]models.BillingRecord {
+	grouped := make(map[string][]models.BillingRecord)
+
+	for _, record := range records {
+		group := UngroupedVPC
+		if name, exists := groups.Groups[vpcIDFromMetadata(record.Metadata)]; exists {
+			group = name
+		}
+		grouped[group] = append(grouped[group], record)
+	}
+
+	return grouped
+}
+
+// vpcIDFromMetadata returns the record's VPC/VNet ID from the first of vpcMetadataKeys
+// present in its metadata
+func vpcIDFromMetadata(metadata map[string]string) string {
+	for _, candidate := range vpcMetadataKeys {
+		if value := metadataValue(metadata, candidate); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// UnassignedGroup is the group name for records without a value for the split dimension
+const UnassignedGroup = "Unassigned"
+
+// SplitDimensions lists the accepted GroupByDimension dimensions besides tag:<key>
+var SplitDimensions = []string{"provider", "region", "project"}
+
+// ValidateSplitDimension checks a -split-billing-by value
+func ValidateSplitDimension(dimension string) error {
+	if key, isTag := strings.CutPrefix(dimension, "tag:"); isTag {
+		if key == "" {
+			return fmt.Errorf("split dimension %q has no tag key", dimension)
+		}
+		return nil
+	}
+	for _, d := range SplitDimensions {
+		if dimension == d {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown split dimension %q (expected %s or tag:<key>)", dimension, strings.Join(SplitDimensions, ", "))
+}
+
+// GroupByDimension partitions records by provider, region, project or tag:<key>, where the
+// tag is read from record metadata (key matched case-insensitively). Records without a
+// value go to UnassignedGroup.
+func GroupByDimension(recordsByProvider map[string][]models.BillingRecord, dimension string) (map[string][]models.BillingRecord, error) {
+	if err := ValidateSplitDimension(dimension); err != nil {
+		return nil, err
+	}
+	tagKey, isTag := strings.CutPrefix(dimension, "tag:")
+
+	grouped := make(map[string][]models.BillingRecord)
+	for provider, records := range recordsByProvider {
+		for _, record := range records {
+			var group string
+			switch {
+			case isTag:
+				group = metadataValue(record.Metadata, tagKey)
+			case dimension == "provider":
+				group = provider
+			case dimension == "region":
+				group = record.Region
+			case dimension == "project":
+				group = record.Project
+			}
+			if group == "" {
+				group = UnassignedGroup
+			}
+			grouped[group] = append(grouped[group], record)
+		}
+	}
+
+	return grouped, nil
+}
+
+// metadataValue returns the metadata value for key, matched case-insensitively
+func metadataValue(metadata map[string]string, key string) string {
+	for k, v := range metadata {
+		if strings.EqualFold(k, key) {
+			return v
+		}
+	}
+	return ""
+}
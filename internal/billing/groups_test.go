@@ -0,0 +1,37 @@
+package billing
+
+import (
+	"testing"
+
+	"github.com/ozwilder/CloudCostCalaCLI/internal/config"
+	"github.com/ozwilder/CloudCostCalaCLI/internal/models"
+)
+
+func TestGroupByVPCChecksKeysInPriorityOrder(t *testing.T) {
+	groups := config.VPCGroupConfig{Groups: map[string]string{"vpc-1": "core", "vnet-9": "edge"}}
+	record := models.BillingRecord{
+		ResourceID: "i-1",
+		Metadata:   map[string]string{"VNet_ID": "vnet-9", "networkid": "net-3", "VpcId": "vpc-1"},
+	}
+
+	// Map order varies between runs, so repeat to catch a nondeterministic pick
+	for i := 0; i < 50; i++ {
+		grouped := GroupByVPC([]models.BillingRecord{record}, groups)
+		if len(grouped["core"]) != 1 {
+			t.Fatalf("run %d: groups = %v, want the record in core (vpcid before vnet_id)", i, grouped)
+		}
+	}
+}
+
+func TestGroupByVPCUngrouped(t *testing.T) {
+	groups := config.VPCGroupConfig{Groups: map[string]string{"vpc-1": "core"}}
+	records := []models.BillingRecord{
+		{ResourceID: "i-1", Metadata: map[string]string{"vpc_id": "vpc-2"}},
+		{ResourceID: "i-2"},
+	}
+
+	grouped := GroupByVPC(records, groups)
+	if len(grouped[UngroupedVPC]) != 2 {
+		t.Errorf("got %d ungrouped records, want 2", len(grouped[UngroupedVPC]))
+	}
+}
@@ -0,0 +1,258 @@
+package billing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hamba/avro/v2"
+	"github.com/segmentio/kafka-go"
+
+	"github.com/ozwilder/CloudCostCalaCLI/internal/config"
+	"github.com/ozwilder/CloudCostCalaCLI/internal/models"
+)
+
+// defaultKafkaIdleTimeout is how long a consumer waits for a message before treating the
+// topic as idle, when billing.*.kafka.idleTimeoutSeconds is not set; tests shorten it
+var defaultKafkaIdleTimeout = 10 * time.Second
+
+// kafkaMessage is one billing record as published to a Kafka topic. Field names follow
+// the billing CSV columns; resourceType may be left out in favour of service.
+type kafkaMessage struct {
+	Service       string            `json:"service" avro:"service"`
+	ResourceType  string            `json:"resourceType" avro:"resourceType"`
+	ResourceID    string            `json:"resourceId" avro:"resourceId"`
+	InstanceHours float64           `json:"instanceHours" avro:"instanceHours"`
+	Cost          float64           `json:"cost" avro:"cost"`
+	Period        string            `json:"period" avro:"period"`
+	StartTime     string            `json:"startTime" avro:"startTime"`
+	Region        string            `json:"region" avro:"region"`
+	AccountID     string            `json:"accountId" avro:"accountId"`
+	Project       string            `json:"project" avro:"project"`
+	Tags          map[string]string `json:"tags" avro:"tags"`
+}
+
+// kafkaReader is the part of *kafka.Reader used here
+type kafkaReader interface {
+	FetchMessage(ctx context.Context) (kafka.Message, error)
+	CommitMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// newKafkaReader joins a consumer group on a topic; tests replace it with a fake
+var newKafkaReader = func(brokers []string, topic, consumerGroup string) kafkaReader {
+	return kafka.NewReader(kafka.ReaderConfig{Brokers: brokers, Topic: topic, GroupID: consumerGroup})
+}
+
+// kafkaProviders maps provider keys to record provider names and service mappings
+var kafkaProviders = map[string]struct {
+	name        string
+	serviceType func(string) string
+}{
+	"aws":   {"AWS", mapAWSServiceToType},
+	"azure": {"Azure", mapAzureServiceToType},
+	"gcp":   {"GCP", mapGCPServiceToType},
+}
+
+// ConsumeKafkaBilling reads JSON billing messages from a Kafka topic as a member of
+// consumerGroup and sends the decoded records to out until ctx is done, which it returns.
+// Records are sent in batches, and offsets are committed once a batch has been sent.
+func ConsumeKafkaBilling(ctx context.Context, brokers []string, topic, consumerGroup, provider string,
+	out chan<- models.BillingRecord) error {
+	kafkaCfg := config.KafkaConfig{Brokers: brokers, Topic: topic, ConsumerGroup: consumerGroup}
+	return consumeKafka(ctx, kafkaCfg, provider, ParseOptions{}, DefaultBatchSize, false,
+		func(batch []models.BillingRecord) error {
+			for _, record := range batch {
+				select {
+				case out <- record:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+			return nil
+		})
+}
+
+// StreamKafkaBilling reads a provider's billing topic like StreamBillingFile reads a file:
+// records are masked as opts.Masking selects, transformed and passed to flush in batches of
+// batchSize (DefaultBatchSize when below 1). It returns once no message has arrived for the
+// configured idle timeout, so a run processes the backlog published since the group's last
+// commit. Offsets are committed after each successful flush; undecodable messages are
+// recorded in opts.Audit and skipped.
+func StreamKafkaBilling(ctx context.Context, kafkaCfg config.KafkaConfig, cloudProvider string, opts ParseOptions,
+	batchSize int, flush func([]models.BillingRecord) error) error {
+	return consumeKafka(ctx, kafkaCfg, cloudProvider, opts, batchSize, true, flush)
+}
+
+// consumeKafka reads a billing topic into batches. When the topic is idle the pending batch
+// is flushed, and reading stops if stopWhenIdle is set.
+func consumeKafka(ctx context.Context, kafkaCfg config.KafkaConfig, cloudProvider string, opts ParseOptions,
+	batchSize int, stopWhenIdle bool, flush func([]models.BillingRecord) error) error {
+	provider, exists := kafkaProviders[strings.ToLower(cloudProvider)]
+	if !exists {
+		return fmt.Errorf("unknown cloud provider: %s", cloudProvider)
+	}
+	decode, err := kafkaDecoder(kafkaCfg)
+	if err != nil {
+		return err
+	}
+
+	group := kafkaCfg.ConsumerGroup
+	if group == "" {
+		group = "cloudcostcala-" + strings.ToLower(cloudProvider)
+	}
+	idleTimeout := defaultKafkaIdleTimeout
+	if kafkaCfg.IdleTimeoutSeconds > 0 {
+		idleTimeout = time.Duration(kafkaCfg.IdleTimeoutSeconds) * time.Second
+	}
+
+	reader := newKafkaReader(kafkaCfg.Brokers, kafkaCfg.Topic, group)
+	defer reader.Close()
+
+	// Messages read since the last commit; committed only after their records are flushed
+	pending := make([]kafka.Message, 0)
+	commit := func() error {
+		if len(pending) == 0 {
+			return nil
+		}
+		if err := reader.CommitMessages(ctx, pending...); err != nil {
+			return fmt.Errorf("failed to commit %s Kafka offsets: %w", provider.name, err)
+		}
+		pending = pending[:0]
+		return nil
+	}
+	batches := newRecordBatcher(batchSize, opts.Transform, func(batch []models.BillingRecord) error {
+		if err := flush(batch); err != nil {
+			return err
+		}
+		return commit()
+	})
+
+	for {
+		fetchCtx, cancel := context.WithTimeout(ctx, idleTimeout)
+		msg, err := reader.FetchMessage(fetchCtx)
+		cancel()
+		if err != nil {
+			if ctx.Err() == nil && errors.Is(err, context.DeadlineExceeded) {
+				// The topic is idle: hand over what has been read so far
+				if err := batches.flush(); err != nil {
+					return err
+				}
+				if err := commit(); err != nil {
+					return err
+				}
+				if stopWhenIdle {
+					return nil
+				}
+				continue
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("failed to read %s billing from Kafka: %w", provider.name, err)
+		}
+
+		pending = append(pending, msg)
+		message, err := decode(msg.Value)
+		if err != nil {
+			opts.Audit.Add(AuditEntry{RowIndex: int(msg.Offset), Provider: provider.name,
+				Reason: ReasonUndecodable, RawRow: []string{string(msg.Value)}})
+			continue
+		}
+		if err := batches.add(kafkaRecord(message, provider.name, provider.serviceType, opts)); err != nil {
+			return err
+		}
+	}
+}
+
+// kafkaDecoder returns the message decoder for the configured format
+func kafkaDecoder(kafkaCfg config.KafkaConfig) (func([]byte) (kafkaMessage, error), error) {
+	switch kafkaCfg.Format {
+	case "", "json":
+		return func(value []byte) (kafkaMessage, error) {
+			var message kafkaMessage
+			err := json.Unmarshal(value, &message)
+			return message, err
+		}, nil
+	case "avro", "avro-confluent":
+		data, err := os.ReadFile(kafkaCfg.AvroSchemaFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read Avro schema: %w", err)
+		}
+		schema, err := avro.Parse(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse Avro schema: %w", err)
+		}
+		confluent := kafkaCfg.Format == "avro-confluent"
+		return func(value []byte) (kafkaMessage, error) {
+			var message kafkaMessage
+			if confluent {
+				// Confluent serializers put a zero magic byte and a 4-byte schema ID first
+				if len(value) < 5 || value[0] != 0 {
+					return message, fmt.Errorf("missing Confluent wire format header")
+				}
+				value = value[5:]
+			}
+			err := avro.Unmarshal(schema, value, &message)
+			return message, err
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown Kafka message format %q", kafkaCfg.Format)
+	}
+}
+
+// kafkaRecord converts a decoded message to a billing record, masking it as opts select
+func kafkaRecord(message kafkaMessage, provider string, serviceType func(string) string,
+	opts ParseOptions) models.BillingRecord {
+	resourceType := message.ResourceType
+	if resourceType == "" {
+		resourceType = serviceType(message.Service)
+	}
+
+	startTime := parseStartTime(message.StartTime)
+	period := message.Period
+	if period == "" && !startTime.IsZero() {
+		period = startTime.Format("2006-01")
+	}
+
+	accountID := message.AccountID
+	if accountID == "" {
+		accountID = opts.AccountID
+	}
+	project := message.Project
+	if project == "" {
+		project = strings.ToLower(provider) + "-default"
+	}
+
+	resourceID := message.ResourceID
+	if opts.Masking.MaskResourceID {
+		resourceID = MaskValue(resourceID)
+	}
+	if opts.Masking.MaskProject {
+		project = MaskValue(project)
+	}
+
+	metadata := message.Tags
+	if metadata == nil {
+		metadata = make(map[string]string)
+	}
+
+	return models.BillingRecord{
+		Provider:      provider,
+		ServiceName:   message.Service,
+		ResourceType:  resourceType,
+		ResourceID:    resourceID,
+		InstanceHours: message.InstanceHours,
+		Cost:          message.Cost,
+		StartTime:     startTime,
+		TimePeriod:    period,
+		Region:        message.Region,
+		AccountID:     accountID,
+		Project:       project,
+		Metadata:      metadata,
+	}
+}
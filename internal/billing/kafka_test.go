@@ -0,0 +1,201 @@
+package billing
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hamba/avro/v2"
+	"github.com/segmentio/kafka-go"
+
+	"github.com/ozwilder/CloudCostCalaCLI/internal/config"
+	"github.com/ozwilder/CloudCostCalaCLI/internal/models"
+)
+
+// fakeKafkaReader serves queued messages, then blocks like an idle topic until the
+// fetch context is done
+type fakeKafkaReader struct {
+	messages  []kafka.Message
+	committed []int64
+	group     string
+	closed    bool
+}
+
+func (r *fakeKafkaReader) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	if len(r.messages) == 0 {
+		<-ctx.Done()
+		return kafka.Message{}, ctx.Err()
+	}
+	msg := r.messages[0]
+	r.messages = r.messages[1:]
+	return msg, nil
+}
+
+func (r *fakeKafkaReader) CommitMessages(ctx context.Context, msgs ...kafka.Message) error {
+	for _, msg := range msgs {
+		r.committed = append(r.committed, msg.Offset)
+	}
+	return nil
+}
+
+func (r *fakeKafkaReader) Close() error {
+	r.closed = true
+	return nil
+}
+
+// useFakeKafkaReader makes newKafkaReader return a reader over values for the duration of the test
+func useFakeKafkaReader(t *testing.T, values ...string) *fakeKafkaReader {
+	t.Helper()
+	reader := &fakeKafkaReader{}
+	for i, value := range values {
+		reader.messages = append(reader.messages, kafka.Message{Offset: int64(i), Value: []byte(value)})
+	}
+	previous := newKafkaReader
+	newKafkaReader = func(brokers []string, topic, consumerGroup string) kafkaReader {
+		reader.group = consumerGroup
+		return reader
+	}
+	t.Cleanup(func() { newKafkaReader = previous })
+	return reader
+}
+
+func TestStreamKafkaBilling(t *testing.T) {
+	reader := useFakeKafkaReader(t,
+		`{"service":"AmazonEC2","resourceId":"i-1","instanceHours":744,"cost":70,"startTime":"2024-01-01T00:00:00Z"}`,
+		`not json`,
+		`{"service":"AmazonRDS","resourceType":"Database","resourceId":"db-1","instanceHours":10,"cost":5,"period":"2024-01","project":"shop"}`,
+		`{"service":"AmazonEC2","resourceId":"i-2","instanceHours":1,"cost":0.1,"period":"2024-01","tags":{"env":"dev"}}`,
+	)
+	audit := &AuditLog{}
+	opts := ParseOptions{Audit: audit, Masking: config.FieldMaskingConfig{MaskResourceID: true}}
+	kafkaCfg := config.KafkaConfig{Brokers: []string{"localhost:9092"}, Topic: "aws-billing", IdleTimeoutSeconds: 1}
+
+	var batches [][]models.BillingRecord
+	err := StreamKafkaBilling(context.Background(), kafkaCfg, "aws", opts, 2, func(batch []models.BillingRecord) error {
+		batches = append(batches, append([]models.BillingRecord(nil), batch...))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StreamKafkaBilling: %v", err)
+	}
+
+	if len(batches) != 2 || len(batches[0]) != 2 || len(batches[1]) != 1 {
+		t.Fatalf("got batches %v, want 2 records then the remainder once the topic is idle", batches)
+	}
+	first := batches[0][0]
+	if first.Provider != "AWS" || first.ResourceType != "VM" || first.TimePeriod != "2024-01" ||
+		first.Project != "aws-default" {
+		t.Errorf("first record = %+v, want an AWS VM for 2024-01 in the default project", first)
+	}
+	if first.ResourceID == "i-1" {
+		t.Errorf("resource ID was not masked")
+	}
+	if got := batches[0][1]; got.ResourceType != "Database" || got.Project != "shop" {
+		t.Errorf("second record = %+v, want the message's type and project", got)
+	}
+	if got := batches[1][0].Metadata["env"]; got != "dev" {
+		t.Errorf("tags = %v, want env=dev", batches[1][0].Metadata)
+	}
+
+	// Every offset, including the undecodable one, is committed once its batch is flushed
+	if len(reader.committed) != 4 {
+		t.Errorf("committed offsets %v, want all 4", reader.committed)
+	}
+	if reader.group != "cloudcostcala-aws" || !reader.closed {
+		t.Errorf("group = %q, closed = %v; want the default group and a closed reader", reader.group, reader.closed)
+	}
+	if len(audit.Entries) != 1 || audit.Entries[0].Reason != ReasonUndecodable || audit.Entries[0].RowIndex != 1 {
+		t.Errorf("audit entries = %+v, want the undecodable message at offset 1", audit.Entries)
+	}
+}
+
+func TestStreamKafkaBillingAvro(t *testing.T) {
+	schemaJSON := `{"type":"record","name":"BillingRecord","fields":[
+		{"name":"service","type":"string","default":""},
+		{"name":"resourceType","type":"string","default":""},
+		{"name":"resourceId","type":"string","default":""},
+		{"name":"instanceHours","type":"double","default":0},
+		{"name":"cost","type":"double","default":0},
+		{"name":"period","type":"string","default":""},
+		{"name":"startTime","type":"string","default":""},
+		{"name":"region","type":"string","default":""},
+		{"name":"accountId","type":"string","default":""},
+		{"name":"project","type":"string","default":""},
+		{"name":"tags","type":{"type":"map","values":"string"},"default":{}}]}`
+	schemaFile := filepath.Join(t.TempDir(), "billing.avsc")
+	if err := os.WriteFile(schemaFile, []byte(schemaJSON), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	schema := avro.MustParse(schemaJSON)
+	value, err := avro.Marshal(schema, kafkaMessage{Service: "Virtual Machines", ResourceID: "vm-1",
+		InstanceHours: 744, Period: "2024-01", Tags: map[string]string{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		format string
+		value  []byte
+	}{
+		{"raw", "avro", value},
+		{"confluent", "avro-confluent", append([]byte{0, 0, 0, 0, 7}, value...)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useFakeKafkaReader(t, string(tt.value))
+			kafkaCfg := config.KafkaConfig{Topic: "azure-billing", Format: tt.format, AvroSchemaFile: schemaFile,
+				IdleTimeoutSeconds: 1}
+
+			var records []models.BillingRecord
+			err := StreamKafkaBilling(context.Background(), kafkaCfg, "azure", ParseOptions{}, 0,
+				func(batch []models.BillingRecord) error {
+					records = append(records, batch...)
+					return nil
+				})
+			if err != nil {
+				t.Fatalf("StreamKafkaBilling: %v", err)
+			}
+			if len(records) != 1 || records[0].ResourceID != "vm-1" || records[0].ResourceType != "VM" ||
+				records[0].InstanceHours != 744 {
+				t.Errorf("records = %+v, want the decoded Azure VM", records)
+			}
+		})
+	}
+}
+
+func TestConsumeKafkaBilling(t *testing.T) {
+	idleTimeout := defaultKafkaIdleTimeout
+	defaultKafkaIdleTimeout = 50 * time.Millisecond
+	defer func() { defaultKafkaIdleTimeout = idleTimeout }()
+	useFakeKafkaReader(t,
+		`{"service":"Compute Engine","resourceId":"gce-1","instanceHours":5,"period":"2024-01"}`,
+		`{"service":"Compute Engine","resourceId":"gce-2","instanceHours":6,"period":"2024-01"}`,
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	out := make(chan models.BillingRecord)
+	done := make(chan error, 1)
+	go func() {
+		done <- ConsumeKafkaBilling(ctx, []string{"localhost:9092"}, "gcp-billing", "", "gcp", out)
+	}()
+
+	for _, want := range []string{"gce-1", "gce-2"} {
+		select {
+		case record := <-out:
+			if record.ResourceID != want || record.Provider != "GCP" {
+				t.Errorf("record = %+v, want GCP %s", record, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no record received for %s", want)
+		}
+	}
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("ConsumeKafkaBilling returned %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,64 @@
+package billing
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/ozwilder/CloudCostCalaCLI/internal/models"
+)
+
+// LintWarning describes a suspicious billing record, usually a CSV export mistake
+type LintWarning struct {
+	Row        int // 1-based position among the checked records
+	ResourceID string
+	Message    string
+}
+
+// String formats the warning for console output
+func (w LintWarning) String() string {
+	return fmt.Sprintf("record %d (%s): %s", w.Row, w.ResourceID, w.Message)
+}
+
+// periodPattern matches billing periods in YYYY-MM format
+var periodPattern = regexp.MustCompile(`^\d{4}-(0[1-9]|1[0-2])$`)
+
+// LintBillingRecords checks records for impossible instance-hours, missing regions,
+// malformed periods, negative costs and duplicate (resource, period) pairs
+func LintBillingRecords(records []models.BillingRecord) []LintWarning {
+	warnings := make([]LintWarning, 0)
+	seen := make(map[string]int)
+
+	for i, record := range records {
+		row := i + 1
+		warn := func(format string, args ...interface{}) {
+			warnings = append(warnings, LintWarning{
+				Row:        row,
+				ResourceID: record.ResourceID,
+				Message:    fmt.Sprintf(format, args...),
+			})
+		}
+
+		if !periodPattern.MatchString(record.TimePeriod) {
+			warn("period %q is not in YYYY-MM format", record.TimePeriod)
+		} else if hours := float64(getDaysInPeriod(record.TimePeriod) * 24); record.InstanceHours > hours {
+			warn("%.2f instance-hours exceeds the %.0f hours in %s", record.InstanceHours, hours, record.TimePeriod)
+		}
+
+		if record.Region == "" {
+			warn("missing region")
+		}
+
+		if record.Cost < 0 {
+			warn("negative cost %.2f; refunds should be handled separately", record.Cost)
+		}
+
+		key := record.ResourceID + "|" + record.TimePeriod
+		if first, exists := seen[key]; exists {
+			warn("duplicate of record %d for period %s", first, record.TimePeriod)
+		} else {
+			seen[key] = row
+		}
+	}
+
+	return warnings
+}
@@ -0,0 +1,50 @@
+package billing
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ozwilder/CloudCostCalaCLI/internal/models"
+)
+
+func TestLintBillingRecords(t *testing.T) {
+	valid := models.BillingRecord{ResourceID: "i-1", InstanceHours: 720, TimePeriod: "2024-01", Region: "us-east-1", Cost: 10}
+
+	tests := []struct {
+		name   string
+		modify func(r *models.BillingRecord)
+		want   string
+	}{
+		{"valid record", func(r *models.BillingRecord) {}, ""},
+		{"malformed period", func(r *models.BillingRecord) { r.TimePeriod = "01/2024" }, "not in YYYY-MM format"},
+		{"impossible hours", func(r *models.BillingRecord) { r.InstanceHours = 800 }, "exceeds the 744 hours"},
+		{"missing region", func(r *models.BillingRecord) { r.Region = "" }, "missing region"},
+		{"negative cost", func(r *models.BillingRecord) { r.Cost = -4.5 }, "refunds should be handled separately"},
+		{"zero cost", func(r *models.BillingRecord) { r.Cost = 0 }, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record := valid
+			tt.modify(&record)
+			warnings := LintBillingRecords([]models.BillingRecord{record})
+			if tt.want == "" {
+				if len(warnings) != 0 {
+					t.Errorf("got warnings %v, want none", warnings)
+				}
+				return
+			}
+			if len(warnings) != 1 || !strings.Contains(warnings[0].Message, tt.want) {
+				t.Errorf("got warnings %v, want one containing %q", warnings, tt.want)
+			}
+		})
+	}
+}
+
+func TestLintBillingRecordsDuplicates(t *testing.T) {
+	record := models.BillingRecord{ResourceID: "i-1", TimePeriod: "2024-01", Region: "us-east-1"}
+	warnings := LintBillingRecords([]models.BillingRecord{record, record})
+	if len(warnings) != 1 || warnings[0].Row != 2 || !strings.Contains(warnings[0].Message, "duplicate of record 1") {
+		t.Errorf("got warnings %v, want one duplicate warning on record 2", warnings)
+	}
+}
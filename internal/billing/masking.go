@@ -0,0 +1,65 @@
+package billing
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"strings"
+
+	"github.com/ozwilder/CloudCostCalaCLI/internal/config"
+	"github.com/ozwilder/CloudCostCalaCLI/internal/models"
+)
+
+// maskedHashLength is how many hex digits of the SHA-256 hash a masked value keeps
+const maskedHashLength = 12
+
+// MaskValue replaces a value with MASKED-<sha256 prefix>, so equal values stay equal.
+// Empty values are left empty.
+func MaskValue(value string) string {
+	if value == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(value))
+	return "MASKED-" + hex.EncodeToString(sum[:])[:maskedHashLength]
+}
+
+// resourceIDHeaders are the resource ID column names of every billing format
+var resourceIDHeaders = [][]string{
+	billingColumns["resourceId"], curV1Columns["resourceId"], curV2Columns["resourceId"],
+}
+
+// maskedColumns returns the indices of the header's columns masked by masking. Every
+// resource ID column is masked, not just the one the parser maps, since the others are
+// kept in Metadata.
+func maskedColumns(header []string, masking config.FieldMaskingConfig) []int {
+	if !masking.MaskResourceID {
+		return nil
+	}
+	var masked []int
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		for _, synonyms := range resourceIDHeaders {
+			if slices.Contains(synonyms, name) {
+				masked = append(masked, i)
+				break
+			}
+		}
+	}
+	return masked
+}
+
+// MaskRecords masks the record fields selected in masking, in place. Files read with
+// ParseOptions.Masking are already masked; this is for records from other sources.
+func MaskRecords(records []models.BillingRecord, masking config.FieldMaskingConfig) {
+	if !masking.MaskResourceID && !masking.MaskProject {
+		return
+	}
+	for i := range records {
+		if masking.MaskResourceID {
+			records[i].ResourceID = MaskValue(records[i].ResourceID)
+		}
+		if masking.MaskProject {
+			records[i].Project = MaskValue(records[i].Project)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package billing
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ozwilder/CloudCostCalaCLI/internal/config"
+)
+
+// maskingCSV has a resource ID column, a second one kept as metadata, and a short row
+const maskingCSV = `service,resourceId,instanceHours,period,region,instanceId
+EC2,alice@example.com,720,2024-01,us-east-1,alice@example.com
+RDS,bob@example.com
+`
+
+func TestParseMasksResourceIDsEverywhere(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "aws.csv")
+	if err := os.WriteFile(path, []byte(maskingCSV), 0644); err != nil {
+		t.Fatal(err)
+	}
+	opts := ParseOptions{
+		Audit:   &AuditLog{},
+		Masking: config.FieldMaskingConfig{MaskResourceID: true, MaskProject: true},
+	}
+
+	records, err := ParseBillingFile(path, "aws", opts)
+	if err != nil {
+		t.Fatalf("ParseBillingFile: %v", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("got %d records, want 1", len(records))
+	}
+	record := records[0]
+	if want := MaskValue("alice@example.com"); record.ResourceID != want {
+		t.Errorf("ResourceID = %q, want %q", record.ResourceID, want)
+	}
+	if want := MaskValue("aws-default"); record.Project != want {
+		t.Errorf("Project = %q, want %q", record.Project, want)
+	}
+	if got := record.Metadata["instanceId"]; got != MaskValue("alice@example.com") {
+		t.Errorf("Metadata[instanceId] = %q, want it masked", got)
+	}
+
+	if len(opts.Audit.Entries) != 1 {
+		t.Fatalf("got %d audit entries, want 1", len(opts.Audit.Entries))
+	}
+	if raw := strings.Join(opts.Audit.Entries[0].RawRow, ","); strings.Contains(raw, "bob@") {
+		t.Errorf("audit RawRow %q holds an unmasked resource ID", raw)
+	}
+
+	_, byType, err := SplitCSVByType(path, "aws", opts)
+	if err != nil {
+		t.Fatalf("SplitCSVByType: %v", err)
+	}
+	for resourceType, rows := range byType {
+		for _, row := range rows {
+			if raw := strings.Join(row, ","); strings.Contains(raw, "alice@") {
+				t.Errorf("split %s row %q holds an unmasked resource ID", resourceType, raw)
+			}
+		}
+	}
+}
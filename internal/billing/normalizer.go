@@ -2,33 +2,80 @@ package billing
 
 import (
 	"fmt"
+	"math"
+	"strings"
+	"time"
 
+	"github.com/ozwilder/CloudCostCalaCLI/internal/config"
 	"github.com/ozwilder/CloudCostCalaCLI/internal/models"
 )
 
-// NormalizeToInstanceHours converts total instance-hours to average instances per hour
-func NormalizeToInstanceHours(records []models.BillingRecord, billingPeriod string) map[string]float64 {
-	daysInPeriod := getDaysInPeriod(billingPeriod)
-	hoursInPeriod := float64(daysInPeriod * 24)
+// NormalizeToInstanceHours converts total instance-hours to average instances per hour.
+// Each record's instance-hours are weighted by its region's coefficient; regions missing
+// from regionCoefficients (or a nil map) count at 1.0.
+func NormalizeToInstanceHours(records []models.BillingRecord, billingPeriod string,
+	regionCoefficients map[string]float64) map[string]float64 {
+	acc := NewInstanceHoursAccumulator(regionCoefficients)
+	acc.Add(records)
+	return acc.Normalize(billingPeriod)
+}
 
-	normalized := make(map[string]float64)
+// InstanceHoursAccumulator sums region-weighted instance-hours by resource type across
+// batches, so streamed records can be normalized without keeping them all
+type InstanceHoursAccumulator struct {
+	regionCoefficients map[string]float64
+	hours              map[string]float64
+}
 
-	// Sum instance-hours by resource type
-	for _, record := range records {
-		normalized[record.ResourceType] += record.InstanceHours
+// NewInstanceHoursAccumulator creates an empty accumulator using the given region weights
+func NewInstanceHoursAccumulator(regionCoefficients map[string]float64) *InstanceHoursAccumulator {
+	return &InstanceHoursAccumulator{
+		regionCoefficients: regionCoefficients,
+		hours:              make(map[string]float64),
 	}
+}
 
-	// Convert total instance-hours to average instances per hour
-	for resourceType := range normalized {
-		normalized[resourceType] = normalized[resourceType] / hoursInPeriod
+// Add sums a batch of records into the accumulator
+func (a *InstanceHoursAccumulator) Add(records []models.BillingRecord) {
+	for _, record := range records {
+		weight := 1.0
+		if coefficient, exists := a.regionCoefficients[record.Region]; exists {
+			weight = coefficient
+		}
+		a.hours[record.ResourceType] += record.InstanceHours * weight
 	}
+}
 
+// Normalize converts the summed instance-hours to average instances per hour over the period
+func (a *InstanceHoursAccumulator) Normalize(billingPeriod string) map[string]float64 {
+	hoursInPeriod := float64(getDaysInPeriod(billingPeriod) * 24)
+
+	normalized := make(map[string]float64, len(a.hours))
+	for resourceType, hours := range a.hours {
+		normalized[resourceType] = hours / hoursInPeriod
+	}
 	return normalized
 }
 
-// getDaysInPeriod returns number of days in a given month
-// Expected format: YYYY-MM
+// RoundInstanceHours rounds each record's instance-hours to the given number of decimal places
+func RoundInstanceHours(records []models.BillingRecord, precision int) {
+	if precision < 0 {
+		return
+	}
+
+	scale := math.Pow(10, float64(precision))
+	for i := range records {
+		records[i].InstanceHours = math.Round(records[i].InstanceHours*scale) / scale
+	}
+}
+
+// getDaysInPeriod returns number of days in a given month, or in an inclusive date range
+// Expected format: YYYY-MM or YYYY-MM-DD/YYYY-MM-DD
 func getDaysInPeriod(period string) int {
+	if days, ok := daysInRange(period); ok {
+		return days
+	}
+
 	if len(period) < 7 {
 		return 30 // Default
 	}
@@ -47,9 +94,188 @@ func getDaysInPeriod(period string) int {
 	}
 }
 
-// AggregateByType groups billing records by resource type and returns normalized instance-hours
-func AggregateByType(records []models.BillingRecord, billingPeriod string) map[string]float64 {
-	return NormalizeToInstanceHours(records, billingPeriod)
+// NormalizeByCost converts total spend to average spend per hour by resource type
+func NormalizeByCost(records []models.BillingRecord, billingPeriod string) map[string]float64 {
+	hoursInPeriod := float64(getDaysInPeriod(billingPeriod) * 24)
+
+	normalized := make(map[string]float64)
+	for _, record := range records {
+		normalized[record.ResourceType] += record.Cost
+	}
+
+	for resourceType := range normalized {
+		normalized[resourceType] = normalized[resourceType] / hoursInPeriod
+	}
+
+	return normalized
+}
+
+// daysInRange returns the number of days in an inclusive YYYY-MM-DD/YYYY-MM-DD range
+func daysInRange(period string) (int, bool) {
+	start, end, found := strings.Cut(period, "/")
+	if !found {
+		return 0, false
+	}
+
+	startDate, err := time.Parse("2006-01-02", strings.TrimSpace(start))
+	if err != nil {
+		return 0, false
+	}
+	endDate, err := time.Parse("2006-01-02", strings.TrimSpace(end))
+	if err != nil || endDate.Before(startDate) {
+		return 0, false
+	}
+
+	return int(endDate.Sub(startDate).Hours()/24) + 1, true
+}
+
+// AggregateByProvider normalizes each provider's records over its own billing period and
+// sums the results by type. Providers without a period in periods use fallbackPeriod.
+func AggregateByProvider(recordsByProvider map[string][]models.BillingRecord, periods map[string]string,
+	fallbackPeriod string, rules config.SyntheticUnitsConfig) map[string]float64 {
+	combined := make(map[string]float64)
+	for provider, records := range recordsByProvider {
+		period := periods[provider]
+		if period == "" {
+			period = fallbackPeriod
+		}
+		for resourceType, value := range AggregateByType(records, period, rules) {
+			combined[resourceType] += value
+		}
+	}
+	return combined
+}
+
+// CostByType sums billed record costs by resource type
+func CostByType(records []models.BillingRecord) map[string]float64 {
+	costs := make(map[string]float64)
+	for _, record := range records {
+		costs[record.ResourceType] += record.Cost
+	}
+	return costs
+}
+
+// SourceRowsByType returns, per resource type, the billing file rows of each provider's
+// records that feed AggregateByType, so synthetic-unit totals can be traced to their source
+func SourceRowsByType(recordsByProvider map[string][]models.BillingRecord) map[string]map[string][]int {
+	lineage := make(map[string]map[string][]int)
+	for provider, records := range recordsByProvider {
+		for _, record := range records {
+			if record.SourceRow == 0 {
+				continue
+			}
+			if lineage[record.ResourceType] == nil {
+				lineage[record.ResourceType] = make(map[string][]int)
+			}
+			lineage[record.ResourceType][provider] = append(lineage[record.ResourceType][provider], record.SourceRow)
+		}
+	}
+	return lineage
+}
+
+// AggregateByType groups billing records by resource type and normalizes each type with its
+// rule's strategy: average instances per hour by default, or average spend per hour for
+// types configured with the spend strategy
+func AggregateByType(records []models.BillingRecord, billingPeriod string, rules config.SyntheticUnitsConfig) map[string]float64 {
+	byInstanceHours := make([]models.BillingRecord, 0, len(records))
+	bySpend := make([]models.BillingRecord, 0)
+	for _, record := range records {
+		if rules.Rules[record.ResourceType].NormalizationStrategy == config.StrategySpend {
+			bySpend = append(bySpend, record)
+		} else {
+			byInstanceHours = append(byInstanceHours, record)
+		}
+	}
+
+	normalized := NormalizeToInstanceHours(byInstanceHours, billingPeriod, rules.RegionCoefficients)
+	for resourceType, value := range NormalizeByCost(bySpend, billingPeriod) {
+		normalized[resourceType] = value
+	}
+
+	return normalized
+}
+
+// ComputeGCPSUD estimates the GCP sustained-use discount rate for a record from the share
+// of its period the resource ran: 0% up to 25% usage, then 10%, 20% and 30% for usage above
+// 25%, 50% and 75% of the period's hours
+func ComputeGCPSUD(record models.BillingRecord) float64 {
+	hoursInPeriod := float64(getDaysInPeriod(record.TimePeriod) * 24)
+	usage := record.InstanceHours / hoursInPeriod
+
+	switch {
+	case usage > 0.75:
+		return 0.30
+	case usage > 0.50:
+		return 0.20
+	case usage > 0.25:
+		return 0.10
+	default:
+		return 0
+	}
+}
+
+// HasHourlyData reports whether any record's start time has sub-day precision
+func HasHourlyData(records []models.BillingRecord) bool {
+	for _, record := range records {
+		if hasTimeOfDay(record.StartTime) {
+			return true
+		}
+	}
+	return false
+}
+
+// AggregateByHourOfDay sums instance-hours per hour of day (0-23) of each record's start time.
+// Records without a start time are skipped; use HasHourlyData to check the data is hourly.
+func AggregateByHourOfDay(records []models.BillingRecord) [24]float64 {
+	var byHour [24]float64
+	for _, record := range records {
+		if !record.StartTime.IsZero() {
+			byHour[record.StartTime.Hour()] += record.InstanceHours
+		}
+	}
+	return byHour
+}
+
+// hasTimeOfDay reports whether t carries a time of day rather than just a date
+func hasTimeOfDay(t time.Time) bool {
+	return !t.IsZero() && (t.Hour() != 0 || t.Minute() != 0 || t.Second() != 0)
+}
+
+// SplitByType groups billing records by resource type
+func SplitByType(records []models.BillingRecord) map[string][]models.BillingRecord {
+	byType := make(map[string][]models.BillingRecord)
+	for _, record := range records {
+		byType[record.ResourceType] = append(byType[record.ResourceType], record)
+	}
+	return byType
+}
+
+// SplitByProvider groups billing records by their Provider
+func SplitByProvider(records []models.BillingRecord) map[string][]models.BillingRecord {
+	byProvider := make(map[string][]models.BillingRecord)
+	for _, record := range records {
+		byProvider[record.Provider] = append(byProvider[record.Provider], record)
+	}
+	return byProvider
+}
+
+// SplitByPeriod groups billing records by their own TimePeriod
+func SplitByPeriod(records []models.BillingRecord) map[string][]models.BillingRecord {
+	byPeriod := make(map[string][]models.BillingRecord)
+	for _, record := range records {
+		byPeriod[record.TimePeriod] = append(byPeriod[record.TimePeriod], record)
+	}
+	return byPeriod
+}
+
+// AggregateByTypePeriod normalizes records separately for each period.
+// The outer key is the period (YYYY-MM), the inner key the resource type.
+func AggregateByTypePeriod(records []models.BillingRecord, rules config.SyntheticUnitsConfig) map[string]map[string]float64 {
+	result := make(map[string]map[string]float64)
+	for period, periodRecords := range SplitByPeriod(records) {
+		result[period] = AggregateByType(periodRecords, period, rules)
+	}
+	return result
 }
 
 // GetBillingPeriod extracts period from records (assumes all same period)
@@ -60,6 +286,29 @@ func GetBillingPeriod(records []models.BillingRecord) string {
 	return "2024-01"
 }
 
+// InferBillingPeriod returns the most common TimePeriod across records, so a few stray rows
+// from adjacent months do not decide the period. Ties go to the period seen first.
+func InferBillingPeriod(records []models.BillingRecord) string {
+	if len(records) == 0 {
+		return GetBillingPeriod(records)
+	}
+
+	counts := make(map[string]int)
+	maxCount := 0
+	for _, record := range records {
+		counts[record.TimePeriod]++
+		maxCount = max(maxCount, counts[record.TimePeriod])
+	}
+
+	// Only compare final counts, so a later period cannot win a tie by reaching it first
+	for _, record := range records {
+		if counts[record.TimePeriod] == maxCount {
+			return record.TimePeriod
+		}
+	}
+	return records[0].TimePeriod
+}
+
 // PrintNormalizationExample shows how normalization works
 func PrintNormalizationExample(period string) {
 	daysInPeriod := getDaysInPeriod(period)
@@ -0,0 +1,118 @@
+package billing
+
+import (
+	"math"
+	"testing"
+
+	"github.com/ozwilder/CloudCostCalaCLI/internal/config"
+	"github.com/ozwilder/CloudCostCalaCLI/internal/models"
+)
+
+func TestAggregateByProviderUsesEachProvidersPeriod(t *testing.T) {
+	rules := config.SyntheticUnitsConfig{Rules: map[string]config.SyntheticUnitRule{"VM": {UnitsPerInstance: 1}}}
+	record := models.BillingRecord{ResourceType: "VM", InstanceHours: 168}
+
+	weekly := AggregateByProvider(map[string][]models.BillingRecord{"GCP": {record}},
+		map[string]string{"GCP": "2024-01-01/2024-01-07"}, "2024-01", rules)
+	monthly := AggregateByProvider(map[string][]models.BillingRecord{"AWS": {record}},
+		map[string]string{"AWS": "2024-01"}, "2024-01", rules)
+
+	if got, want := weekly["VM"], 168.0/(7*24); math.Abs(got-want) > 1e-9 {
+		t.Errorf("7-day period: got %v, want %v", got, want)
+	}
+	if got, want := monthly["VM"], 168.0/(31*24); math.Abs(got-want) > 1e-9 {
+		t.Errorf("31-day period: got %v, want %v", got, want)
+	}
+	if weekly["VM"] == monthly["VM"] {
+		t.Errorf("7-day and 31-day periods normalized to the same value %v", weekly["VM"])
+	}
+}
+
+func TestAggregateByProviderFallsBackToDetectedPeriod(t *testing.T) {
+	rules := config.SyntheticUnitsConfig{Rules: map[string]config.SyntheticUnitRule{"VM": {UnitsPerInstance: 1}}}
+	records := map[string][]models.BillingRecord{"AWS": {{ResourceType: "VM", InstanceHours: 744}}}
+
+	got := AggregateByProvider(records, map[string]string{"AWS": ""}, "2024-01", rules)
+	if got["VM"] != 1 {
+		t.Errorf("got %v, want 1 average instance over the fallback period", got["VM"])
+	}
+}
+
+func TestSplitByProviderKeepsGroupPeriods(t *testing.T) {
+	rules := config.SyntheticUnitsConfig{Rules: map[string]config.SyntheticUnitRule{"VM": {UnitsPerInstance: 1}}}
+	// A group mixing providers, as a region or VPC group does
+	group := []models.BillingRecord{
+		{Provider: "AWS", ResourceType: "VM", InstanceHours: 744},
+		{Provider: "GCP", ResourceType: "VM", InstanceHours: 168},
+	}
+
+	byProvider := SplitByProvider(group)
+	if len(byProvider["AWS"]) != 1 || len(byProvider["GCP"]) != 1 {
+		t.Fatalf("SplitByProvider() = %v, want one record per provider", byProvider)
+	}
+	got := AggregateByProvider(byProvider, map[string]string{"GCP": "2024-01-01/2024-01-07"}, "2024-01", rules)
+	if got["VM"] != 2 {
+		t.Errorf("got %v, want 1 AWS instance over January plus 1 GCP instance over the week", got["VM"])
+	}
+}
+
+func TestAggregateByTypeUsesCostForSpendTypes(t *testing.T) {
+	rules := config.SyntheticUnitsConfig{Rules: map[string]config.SyntheticUnitRule{
+		"VM":       {UnitsPerInstance: 5},
+		"Storage":  {UnitsPerInstance: 1, NormalizationStrategy: config.StrategySpend},
+		"Function": {UnitsPerInstance: 1, NormalizationStrategy: config.StrategySpend},
+	}}
+	records := []models.BillingRecord{
+		{ResourceType: "VM", InstanceHours: 744, Cost: 100},
+		{ResourceType: "Storage", InstanceHours: 744, Cost: 1488},
+		{ResourceType: "Function", InstanceHours: 5000, Cost: 372},
+		{ResourceType: "Function", InstanceHours: 5000, Cost: 372},
+	}
+
+	got := AggregateByType(records, "2024-01", rules)
+	want := map[string]float64{
+		"VM":       1, // 744 instance-hours / 744 hours
+		"Storage":  2, // $1488 / 744 hours
+		"Function": 1, // $744 / 744 hours, instance-hours ignored
+	}
+	for assetType, w := range want {
+		if math.Abs(got[assetType]-w) > 1e-9 {
+			t.Errorf("%s = %v, want %v", assetType, got[assetType], w)
+		}
+	}
+}
+
+func TestNormalizeByCost(t *testing.T) {
+	records := []models.BillingRecord{
+		{ResourceType: "Storage", Cost: 168},
+		{ResourceType: "Storage", Cost: 168},
+	}
+	got := NormalizeByCost(records, "2024-01-01/2024-01-07")
+	if got["Storage"] != 2 {
+		t.Errorf("Storage = %v, want 2 per hour over 7 days", got["Storage"])
+	}
+}
+
+func TestInferBillingPeriod(t *testing.T) {
+	tests := []struct {
+		name    string
+		periods []string
+		want    string
+	}{
+		{"majority", []string{"2024-01", "2024-02", "2024-02"}, "2024-02"},
+		{"tie goes to first seen", []string{"2024-01", "2024-02", "2024-02", "2024-01"}, "2024-01"},
+		{"three-way tie", []string{"2024-03", "2024-01", "2024-02"}, "2024-03"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records := make([]models.BillingRecord, len(tt.periods))
+			for i, period := range tt.periods {
+				records[i].TimePeriod = period
+			}
+			if got := InferBillingPeriod(records); got != tt.want {
+				t.Errorf("InferBillingPeriod(%v) = %q, want %q", tt.periods, got, tt.want)
+			}
+		})
+	}
+}
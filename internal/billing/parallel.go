@@ -0,0 +1,59 @@
+package billing
+
+import (
+	"sync"
+
+	"github.com/ozwilder/CloudCostCalaCLI/internal/models"
+)
+
+// ParallelNormalize is a concurrent NormalizeToInstanceHours for large record sets. Records
+// are split into contiguous shards, each summed into its own map by a worker goroutine,
+// and the shard maps are merged once all workers finish. Results can differ from the
+// sequential version in the last floating-point digits because sums are grouped differently.
+func ParallelNormalize(records []models.BillingRecord, period string, workers int) map[string]float64 {
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(records) {
+		workers = len(records)
+	}
+	if workers <= 1 {
+		return NormalizeToInstanceHours(records, period, nil)
+	}
+
+	shards := make([]map[string]float64, workers)
+	shardSize := (len(records) + workers - 1) / workers
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		start := w * shardSize
+		end := start + shardSize
+		if end > len(records) {
+			end = len(records)
+		}
+
+		wg.Add(1)
+		go func(w int, shard []models.BillingRecord) {
+			defer wg.Done()
+			local := make(map[string]float64)
+			for _, record := range shard {
+				local[record.ResourceType] += record.InstanceHours
+			}
+			shards[w] = local
+		}(w, records[start:end])
+	}
+	wg.Wait()
+
+	hoursInPeriod := float64(getDaysInPeriod(period) * 24)
+	normalized := make(map[string]float64)
+	for _, shard := range shards {
+		for resourceType, hours := range shard {
+			normalized[resourceType] += hours
+		}
+	}
+	for resourceType := range normalized {
+		normalized[resourceType] = normalized[resourceType] / hoursInPeriod
+	}
+
+	return normalized
+}
@@ -0,0 +1,54 @@
+package billing
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+	"testing"
+
+	"github.com/ozwilder/CloudCostCalaCLI/internal/models"
+)
+
+// syntheticRecords builds n records spread over a handful of resource types
+func syntheticRecords(n int) []models.BillingRecord {
+	types := []string{"VM", "Database", "Container", "Function", "Storage"}
+	records := make([]models.BillingRecord, n)
+	for i := range records {
+		records[i] = models.BillingRecord{
+			ResourceType:  types[i%len(types)],
+			ResourceID:    fmt.Sprintf("r-%d", i),
+			InstanceHours: float64(i%744) + 0.5,
+		}
+	}
+	return records
+}
+
+func TestParallelNormalizeMatchesSequential(t *testing.T) {
+	records := syntheticRecords(10000)
+	want := NormalizeToInstanceHours(records, "2024-01", nil)
+
+	for _, workers := range []int{0, 1, 3, 8, 20000} {
+		got := ParallelNormalize(records, "2024-01", workers)
+		for resourceType, value := range want {
+			if math.Abs(got[resourceType]-value) > 1e-9*value {
+				t.Errorf("workers=%d %s = %v, want %v", workers, resourceType, got[resourceType], value)
+			}
+		}
+	}
+}
+
+func BenchmarkNormalizeSequential(b *testing.B) {
+	records := syntheticRecords(1_000_000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		NormalizeToInstanceHours(records, "2024-01", nil)
+	}
+}
+
+func BenchmarkNormalizeParallel(b *testing.B) {
+	records := syntheticRecords(1_000_000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ParallelNormalize(records, "2024-01", runtime.NumCPU())
+	}
+}
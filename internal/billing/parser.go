@@ -1,160 +1,686 @@
 package billing
 
 import (
+	"bufio"
 	"encoding/csv"
 	"fmt"
+	"io"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/ozwilder/CloudCostCalaCLI/internal/config"
 	"github.com/ozwilder/CloudCostCalaCLI/internal/models"
+	"golang.org/x/text/transform"
 )
 
-// ParseBillingFile reads a billing CSV and converts to BillingRecords
-func ParseBillingFile(filePath, cloudProvider string) ([]models.BillingRecord, error) {
+// StdinPath is the billing file path that reads from standard input
+const StdinPath = "-"
+
+// stdin is read for StdinPath; tests replace it with a pipe
+var stdin io.Reader = os.Stdin
+
+// ParseOptions controls how billing files are read
+type ParseOptions struct {
+	DetectEncoding bool              // Detect and decode non-UTF-8 files before parsing
+	AccountID      string            // Account/subscription/project ID for rows without an account column
+	GCPFormat      string            // GCP billing export format: "csv" (default) or "json"
+	SkipRows       map[string]int    // Rows to skip before the CSV header, keyed by provider (aws, azure, gcp)
+	Audit          *AuditLog         // Collects discarded rows when set
+	Marketplace    bool              // Route AWS Marketplace charges to MarketplaceType
+	MaxFileSizeMB  int               // Refuse billing files larger than this many MB (0 means no limit)
+	AWSAccountMap  map[string]string // AWS account ID -> friendly name, used as the record's Project
+	AzureTimezone  string            // IANA time zone of Azure timestamps without an offset; converted to UTC when set
+	Transform      *Transform        // Script applied to every parsed record when set
+
+	// Masking masks fields as rows are read, so audit entries, Metadata and split files
+	// never see the original values
+	Masking config.FieldMaskingConfig
+}
+
+// MarketplaceType is the resource type for AWS Marketplace charges when they are routed separately
+const MarketplaceType = "Marketplace"
+
+// Parser reads a cloud provider's billing file into BillingRecords
+type Parser interface {
+	Parse(filePath, cloudProvider string) ([]models.BillingRecord, error)
+}
+
+// FileParser is the default Parser, reading billing files with ParseBillingFile
+type FileParser struct {
+	Options ParseOptions
+}
+
+// Parse implements Parser
+func (p FileParser) Parse(filePath, cloudProvider string) ([]models.BillingRecord, error) {
+	return ParseBillingFile(filePath, cloudProvider, p.Options)
+}
+
+// ParseBillingFile reads a billing CSV and converts to BillingRecords, then applies the
+// transform script when one is set
+func ParseBillingFile(filePath, cloudProvider string, opts ParseOptions) ([]models.BillingRecord, error) {
+	records, err := parseProviderFile(filePath, cloudProvider, opts)
+	if err != nil || opts.Transform == nil {
+		return records, err
+	}
+
+	if err := opts.Transform.Apply(records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// recordStream parses a billing file, passing each record to emit as it is read
+type recordStream func(filePath string, opts ParseOptions, emit func(models.BillingRecord) error) error
+
+// providerStream returns the provider's billing file parser
+func providerStream(cloudProvider string, opts ParseOptions) (recordStream, error) {
+	var stream recordStream
 	switch cloudProvider {
 	case "aws":
-		return parseAWSBilling(filePath)
+		stream = streamAWSBilling
 	case "azure":
-		return parseAzureBilling(filePath)
+		stream = streamAzureBilling
 	case "gcp":
-		return parseGCPBilling(filePath)
+		stream = streamGCPBilling
+		if opts.GCPFormat == "json" {
+			stream = streamGCPBillingJSON
+		}
 	default:
 		return nil, fmt.Errorf("unknown cloud provider: %s", cloudProvider)
 	}
+
+	if !opts.Masking.MaskProject {
+		return stream, nil
+	}
+	// Projects come from the account map or provider defaults rather than a CSV cell
+	return func(filePath string, opts ParseOptions, emit func(models.BillingRecord) error) error {
+		return stream(filePath, opts, func(record models.BillingRecord) error {
+			record.Project = MaskValue(record.Project)
+			return emit(record)
+		})
+	}, nil
 }
 
-// parseAWSBilling handles AWS Cost and Usage Report format
-func parseAWSBilling(filePath string) ([]models.BillingRecord, error) {
-	file, err := os.Open(filePath)
+// parseProviderFile reads every record of a billing file with the provider's parser
+func parseProviderFile(filePath, cloudProvider string, opts ParseOptions) ([]models.BillingRecord, error) {
+	stream, err := providerStream(cloudProvider, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []models.BillingRecord
+	err = stream(filePath, opts, func(record models.BillingRecord) error {
+		records = append(records, record)
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to open AWS billing file: %w", err)
+		return nil, err
+	}
+	return records, nil
+}
+
+// streamAWSBilling handles AWS Cost and Usage Report format
+func streamAWSBilling(filePath string, opts ParseOptions, emit func(models.BillingRecord) error) error {
+	file, err := openBillingCSV(filePath, "AWS", opts)
+	if err != nil {
+		return err
 	}
 	defer file.Close()
 
-	reader := csv.NewReader(file)
-	records, err := reader.ReadAll()
+	header := file.header
+	if header == nil {
+		return nil
+	}
+
+	version, err := DetectCURVersion(header)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read AWS billing CSV: %w", err)
+		return fmt.Errorf("invalid AWS billing CSV header: %w", err)
+	}
+	required, optionalSynonyms := billingColumns, optionalBillingColumns
+	switch version {
+	case CURVersion1:
+		required, optionalSynonyms = curV1Columns, curV1OptionalColumns
+	case CURVersion2:
+		required, optionalSynonyms = curV2Columns, curV2OptionalColumns
 	}
 
-	var billingRecords []models.BillingRecord
+	columns, err := detectColumnIndices(header, required)
+	if err != nil {
+		return fmt.Errorf("invalid AWS billing CSV header: %w", err)
+	}
+
+	optional := detectOptionalColumns(header, optionalSynonyms)
 
-	// Skip header (first row)
-	for i := 1; i < len(records); i++ {
-		if len(records[i]) < 6 {
+	for {
+		row, err := file.next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read AWS billing CSV: %w", err)
+		}
+		i := file.row
+		if !hasColumns(row, columns) {
+			opts.Audit.Add(AuditEntry{RowIndex: i, Provider: "AWS", Reason: ReasonMissingColumns, RawRow: row})
 			continue
 		}
 
-		// Expected columns: service,resourceType,resourceId,instanceHours,period,region
-		serviceType := records[i][0]
+		serviceType := row[columns["service"]]
 		resourceType := mapAWSServiceToType(serviceType)
-		resourceID := records[i][2]
-		instanceHours, _ := strconv.ParseFloat(records[i][3], 64)
-		period := records[i][4]
-		region := records[i][5]
+		isMarketplace := columnValue(row, optional, "vendorCode") != ""
+		if isMarketplace && opts.Marketplace {
+			resourceType = MarketplaceType
+		}
+		resourceID := row[columns["resourceId"]]
+		instanceHours, _ := strconv.ParseFloat(row[columns["instanceHours"]], 64)
+		period := row[columns["period"]]
+		if version != CURVersionNone && len(period) >= 7 {
+			// CUR periods are billing period start timestamps
+			period = period[:7]
+		}
+		region := row[columns["region"]]
+		cost, _ := strconv.ParseFloat(columnValue(row, optional, "cost"), 64)
+		accountID := columnValue(row, optional, "accountId")
+		if accountID == "" {
+			accountID = opts.AccountID
+		}
+		project := "aws-default"
+		if name, ok := opts.AWSAccountMap[accountID]; ok {
+			project = name
+		}
 
-		billingRecords = append(billingRecords, models.BillingRecord{
+		if err := emit(models.BillingRecord{
+			Provider:      "AWS",
 			ServiceName:   serviceType,
 			ResourceType:  resourceType,
 			ResourceID:    resourceID,
+			SourceRow:     i,
 			InstanceHours: instanceHours,
+			Cost:          cost,
+			StartTime:     parseStartTime(columnValue(row, optional, "startTime")),
 			TimePeriod:    period,
 			Region:        region,
-			Project:       "aws-default",
-			Metadata:      make(map[string]string),
-		})
+			AccountID:     accountID,
+			Project:       project,
+			IsMarketplace: isMarketplace,
+			Metadata:      extraColumns(header, row, columns, optional),
+		}); err != nil {
+			return err
+		}
 	}
-
-	return billingRecords, nil
 }
 
-// parseAzureBilling handles Azure Cost Management format
-func parseAzureBilling(filePath string) ([]models.BillingRecord, error) {
-	file, err := os.Open(filePath)
+// streamAzureBilling handles Azure Cost Management format
+func streamAzureBilling(filePath string, opts ParseOptions, emit func(models.BillingRecord) error) error {
+	file, err := openBillingCSV(filePath, "Azure", opts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open Azure billing file: %w", err)
+		return err
 	}
 	defer file.Close()
 
-	reader := csv.NewReader(file)
-	records, err := reader.ReadAll()
+	header := file.header
+	if header == nil {
+		return nil
+	}
+
+	columns, err := detectColumnIndices(header, billingColumns)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read Azure billing CSV: %w", err)
+		return fmt.Errorf("invalid Azure billing CSV header: %w", err)
 	}
 
-	var billingRecords []models.BillingRecord
+	var location *time.Location
+	if opts.AzureTimezone != "" {
+		if location, err = time.LoadLocation(opts.AzureTimezone); err != nil {
+			return fmt.Errorf("invalid Azure timezone: %w", err)
+		}
+	}
+
+	optional := detectOptionalColumns(header, optionalBillingColumns)
 
-	// Skip header
-	for i := 1; i < len(records); i++ {
-		if len(records[i]) < 6 {
+	for {
+		row, err := file.next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read Azure billing CSV: %w", err)
+		}
+		i := file.row
+		if !hasColumns(row, columns) {
+			opts.Audit.Add(AuditEntry{RowIndex: i, Provider: "Azure", Reason: ReasonMissingColumns, RawRow: row})
 			continue
 		}
 
-		serviceType := records[i][0]
+		serviceType := row[columns["service"]]
 		resourceType := mapAzureServiceToType(serviceType)
-		resourceID := records[i][2]
-		instanceHours, _ := strconv.ParseFloat(records[i][3], 64)
-		period := records[i][4]
-		region := records[i][5]
+		resourceID := row[columns["resourceId"]]
+		instanceHours, _ := strconv.ParseFloat(row[columns["instanceHours"]], 64)
+		period := row[columns["period"]]
+		region := row[columns["region"]]
+		cost, _ := strconv.ParseFloat(columnValue(row, optional, "cost"), 64)
+		accountID := columnValue(row, optional, "accountId")
+		if accountID == "" {
+			accountID = opts.AccountID
+		}
 
-		billingRecords = append(billingRecords, models.BillingRecord{
+		startTime := parseStartTime(columnValue(row, optional, "startTime"))
+		if location != nil {
+			// Local timestamps can fall in a different month once converted to UTC
+			value := columnValue(row, optional, "startTime")
+			if value == "" {
+				value = period
+			}
+			if utc := parseStartTimeIn(value, location); !utc.IsZero() {
+				startTime = utc
+				period = utc.Format("2006-01")
+			}
+		}
+
+		if err := emit(models.BillingRecord{
+			Provider:      "Azure",
 			ServiceName:   serviceType,
 			ResourceType:  resourceType,
 			ResourceID:    resourceID,
+			SourceRow:     i,
 			InstanceHours: instanceHours,
+			Cost:          cost,
+			StartTime:     startTime,
 			TimePeriod:    period,
 			Region:        region,
+			AccountID:     accountID,
 			Project:       "azure-default",
-			Metadata:      make(map[string]string),
-		})
+			Metadata:      extraColumns(header, row, columns, optional),
+		}); err != nil {
+			return err
+		}
 	}
-
-	return billingRecords, nil
 }
 
-// parseGCPBilling handles GCP billing export format
-func parseGCPBilling(filePath string) ([]models.BillingRecord, error) {
-	file, err := os.Open(filePath)
+// streamGCPBilling handles GCP billing export format
+func streamGCPBilling(filePath string, opts ParseOptions, emit func(models.BillingRecord) error) error {
+	file, err := openBillingCSV(filePath, "GCP", opts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open GCP billing file: %w", err)
+		return err
 	}
 	defer file.Close()
 
-	reader := csv.NewReader(file)
-	records, err := reader.ReadAll()
+	header := file.header
+	if header == nil {
+		return nil
+	}
+
+	columns, err := detectColumnIndices(header, billingColumns)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read GCP billing CSV: %w", err)
+		return fmt.Errorf("invalid GCP billing CSV header: %w", err)
 	}
 
-	var billingRecords []models.BillingRecord
+	optional := detectOptionalColumns(header, optionalBillingColumns)
 
-	// Skip header
-	for i := 1; i < len(records); i++ {
-		if len(records[i]) < 6 {
+	for {
+		row, err := file.next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read GCP billing CSV: %w", err)
+		}
+		i := file.row
+		if !hasColumns(row, columns) {
+			opts.Audit.Add(AuditEntry{RowIndex: i, Provider: "GCP", Reason: ReasonMissingColumns, RawRow: row})
 			continue
 		}
 
-		serviceType := records[i][0]
+		serviceType := row[columns["service"]]
 		resourceType := mapGCPServiceToType(serviceType)
-		resourceID := records[i][2]
-		instanceHours, _ := strconv.ParseFloat(records[i][3], 64)
-		period := records[i][4]
-		region := records[i][5]
+		resourceID := row[columns["resourceId"]]
+		instanceHours, _ := strconv.ParseFloat(row[columns["instanceHours"]], 64)
+		period := row[columns["period"]]
+		region := row[columns["region"]]
+		cost, _ := strconv.ParseFloat(columnValue(row, optional, "cost"), 64)
+		accountID := columnValue(row, optional, "accountId")
+		if accountID == "" {
+			accountID = opts.AccountID
+		}
 
-		billingRecords = append(billingRecords, models.BillingRecord{
+		if err := emit(models.BillingRecord{
+			Provider:      "GCP",
 			ServiceName:   serviceType,
 			ResourceType:  resourceType,
 			ResourceID:    resourceID,
+			SourceRow:     i,
 			InstanceHours: instanceHours,
+			Cost:          cost,
+			StartTime:     parseStartTime(columnValue(row, optional, "startTime")),
 			TimePeriod:    period,
 			Region:        region,
+			AccountID:     accountID,
 			Project:       "gcp-default",
-			Metadata:      make(map[string]string),
-		})
+			Metadata:      extraColumns(header, row, columns, optional),
+		}); err != nil {
+			return err
+		}
+	}
+}
+
+// readBillingCSV opens a billing file and returns all of its CSV rows
+func readBillingCSV(filePath, provider string, opts ParseOptions) ([][]string, error) {
+	file, err := openBillingCSV(filePath, provider, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if file.header == nil {
+		return nil, nil
+	}
+
+	records := [][]string{file.header}
+	for {
+		row, err := file.next()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s billing CSV: %w", provider, err)
+		}
+		records = append(records, row)
+	}
+}
+
+// billingCSV reads a billing file's CSV rows one at a time
+type billingCSV struct {
+	file   io.Closer
+	reader *csv.Reader
+	header []string // Nil when the file is empty
+	row    int      // Index of the row last returned by next; the header is row 0
+	masked []int    // Columns masked in every row returned by next
+}
+
+// openBillingCSV opens a billing file and reads its header row
+func openBillingCSV(filePath, provider string, opts ParseOptions) (*billingCSV, error) {
+	file, err := openBillingFile(filePath, opts.MaxFileSizeMB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s billing file: %w", provider, err)
+	}
+
+	var input io.Reader = file
+	if opts.DetectEncoding {
+		// Peek instead of re-opening the file so stdin can be detected too
+		buffered := bufio.NewReaderSize(file, encodingSampleSize)
+		sample, _ := buffered.Peek(encodingSampleSize)
+		enc := detectEncoding(sample, len(sample) < encodingSampleSize)
+		input = transform.NewReader(buffered, enc.NewDecoder())
 	}
 
-	return billingRecords, nil
+	// Skip informational lines some exports put above the header
+	skip := opts.SkipRows[strings.ToLower(provider)]
+	if skip > 0 {
+		buffered := bufio.NewReader(input)
+		for i := 0; i < skip; i++ {
+			if _, err := buffered.ReadString('\n'); err != nil {
+				break
+			}
+		}
+		input = buffered
+	}
+
+	reader := csv.NewReader(input)
+	reader.FieldsPerRecord = -1 // Short rows are discarded (and audited) by the parsers
+	header, err := reader.Read()
+	if err == io.EOF {
+		return &billingCSV{file: file, reader: reader}, nil
+	}
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to read %s billing CSV: %w", provider, err)
+	}
+
+	return &billingCSV{file: file, reader: reader, header: header, masked: maskedColumns(header, opts.Masking)}, nil
+}
+
+// next returns the next data row, or io.EOF after the last one
+func (c *billingCSV) next() ([]string, error) {
+	row, err := c.reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	c.row++
+	for _, j := range c.masked {
+		if j < len(row) {
+			row[j] = MaskValue(row[j])
+		}
+	}
+	return row, nil
+}
+
+// Close closes the underlying file
+func (c *billingCSV) Close() error {
+	return c.file.Close()
+}
+
+// openBillingFile opens a billing file, or stdin when the path is StdinPath. Files larger
+// than maxSizeMB (when positive) are rejected before any parsing; stdin is not checked.
+func openBillingFile(filePath string, maxSizeMB int) (io.ReadCloser, error) {
+	if filePath == StdinPath {
+		return io.NopCloser(stdin), nil
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	if maxSizeMB > 0 {
+		info, err := file.Stat()
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		if limit := int64(maxSizeMB) << 20; info.Size() > limit {
+			file.Close()
+			return nil, fmt.Errorf("%s is %.1f MB, larger than the %d MB limit", filePath, float64(info.Size())/(1<<20), maxSizeMB)
+		}
+	}
+	return file, nil
+}
+
+// AWS Cost and Usage Report schema versions returned by DetectCURVersion
+const (
+	CURVersionNone = 0 // Not a CUR export; generic column synonyms apply
+	CURVersion1    = 1 // CUR 1.0, category/ColumnName headers
+	CURVersion2    = 2 // CUR 2.0 (Data Exports), snake_case headers
+)
+
+// DetectCURVersion identifies the CUR schema of an AWS billing header from its sentinel
+// columns: bill/BillingEntity for CUR 1.0 and split_line_item_split_cost for CUR 2.0.
+// Headers with neither are CURVersionNone; headers with both are rejected.
+func DetectCURVersion(headers []string) (int, error) {
+	v1, v2 := false, false
+	for _, header := range headers {
+		switch strings.ToLower(strings.TrimSpace(strings.TrimPrefix(header, "\ufeff"))) {
+		case "bill/billingentity":
+			v1 = true
+		case "split_line_item_split_cost":
+			v2 = true
+		}
+	}
+
+	switch {
+	case v1 && v2:
+		return CURVersionNone, fmt.Errorf("header has both CUR 1.0 (bill/BillingEntity) and CUR 2.0 (split_line_item_split_cost) columns")
+	case v1:
+		return CURVersion1, nil
+	case v2:
+		return CURVersion2, nil
+	default:
+		return CURVersionNone, nil
+	}
+}
+
+// curV1Columns maps the required billing fields to CUR 1.0 headers
+var curV1Columns = map[string][]string{
+	"service":       {"lineitem/productcode"},
+	"resourceId":    {"lineitem/resourceid"},
+	"instanceHours": {"lineitem/usageamount"},
+	"period":        {"bill/billingperiodstartdate"},
+	"region":        {"product/region", "product/regioncode"},
+}
+
+// curV1OptionalColumns maps the optional billing fields to CUR 1.0 headers
+var curV1OptionalColumns = map[string][]string{
+	"startTime":  {"lineitem/usagestartdate"},
+	"cost":       {"lineitem/unblendedcost"},
+	"vendorCode": {"product/vendorcode", "lineitem/vendorcode"},
+	"accountId":  {"lineitem/usageaccountid", "bill/payeraccountid"},
+}
+
+// curV2Columns maps the required billing fields to CUR 2.0 headers
+var curV2Columns = map[string][]string{
+	"service":       {"line_item_product_code"},
+	"resourceId":    {"line_item_resource_id"},
+	"instanceHours": {"line_item_usage_amount"},
+	"period":        {"bill_billing_period_start_date"},
+	"region":        {"product_region_code", "product_region"},
+}
+
+// curV2OptionalColumns maps the optional billing fields to CUR 2.0 headers
+var curV2OptionalColumns = map[string][]string{
+	"startTime": {"line_item_usage_start_date"},
+	"cost":      {"line_item_unblended_cost"},
+	"accountId": {"line_item_usage_account_id", "bill_payer_account_id"},
+}
+
+// billingColumns lists the accepted header names for each required billing field
+var billingColumns = map[string][]string{
+	"service":       {"service", "servicename", "service_name", "service.description", "metercategory", "product/productname", "lineitem/productcode"},
+	"resourceId":    {"resourceid", "resource_id", "resource.name", "instanceid", "lineitem/resourceid"},
+	"instanceHours": {"instancehours", "instance_hours", "usagehours", "usage_hours", "usagequantity", "quantity", "lineitem/usageamount"},
+	"period":        {"period", "billingperiod", "billing_period", "month", "invoice.month"},
+	"region":        {"region", "location", "resourcelocation", "location.region", "product/region"},
+}
+
+// optionalBillingColumns lists accepted header names for fields that may be absent
+var optionalBillingColumns = map[string][]string{
+	"startTime":  {"starttime", "start_time", "usagestartdate", "usage_start_time", "lineitem/usagestartdate", "usagedatetime", "date"},
+	"cost":       {"cost", "costinbillingcurrency", "pretaxcost", "cost_amount", "lineitem/unblendedcost"},
+	"vendorCode": {"vendorcode", "vendor_code", "product/vendorcode", "lineitem/vendorcode"},
+	"accountId":  {"accountid", "account_id", "lineitem/usageaccountid", "bill/payeraccountid", "subscriptionid", "subscription_id", "projectid", "project_id", "project.id"},
+}
+
+// detectColumnIndices maps each required field to its column index using the
+// accepted header synonyms. Header matching ignores case and surrounding whitespace.
+func detectColumnIndices(headers []string, required map[string][]string) (map[string]int, error) {
+	positions := make(map[string]int, len(headers))
+	for i, header := range headers {
+		name := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(header, "\ufeff")))
+		if _, exists := positions[name]; !exists {
+			positions[name] = i
+		}
+	}
+
+	indices := make(map[string]int, len(required))
+	missing := make([]string, 0)
+	for field, synonyms := range required {
+		found := false
+		for _, synonym := range synonyms {
+			if i, exists := positions[synonym]; exists {
+				indices[field] = i
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, field)
+		}
+	}
+
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("missing required column(s) %s; found headers: %s",
+			strings.Join(missing, ", "), strings.Join(headers, ", "))
+	}
+
+	return indices, nil
+}
+
+// detectOptionalColumns maps each optional field found in the header to its column index
+func detectOptionalColumns(headers []string, optional map[string][]string) map[string]int {
+	indices := make(map[string]int)
+	for field, synonyms := range optional {
+		if found, err := detectColumnIndices(headers, map[string][]string{field: synonyms}); err == nil {
+			indices[field] = found[field]
+		}
+	}
+	return indices
+}
+
+// columnValue returns the value of an optional column, or "" when it is absent
+func columnValue(row []string, columns map[string]int, field string) string {
+	i, exists := columns[field]
+	if !exists || i >= len(row) {
+		return ""
+	}
+	return row[i]
+}
+
+// hasColumns reports whether a row is wide enough to hold every detected column
+func hasColumns(row []string, columns map[string]int) bool {
+	for _, i := range columns {
+		if i >= len(row) {
+			return false
+		}
+	}
+	return true
+}
+
+// extraColumns keeps any columns not mapped to a record field as metadata keyed by header
+func extraColumns(header, row []string, mapped ...map[string]int) map[string]string {
+	used := make(map[int]bool)
+	for _, columns := range mapped {
+		for _, i := range columns {
+			used[i] = true
+		}
+	}
+
+	metadata := make(map[string]string)
+	for j := 0; j < len(row) && j < len(header); j++ {
+		if !used[j] && row[j] != "" {
+			metadata[header[j]] = row[j]
+		}
+	}
+	return metadata
+}
+
+// startTimeLayouts are the timestamp formats accepted for usage start times
+var startTimeLayouts = []string{
+	time.RFC3339, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02 15:04:05 MST",
+	"2006-01-02T15:04", "2006-01-02 15:04", "2006-01-02",
+	"01/02/2006 15:04:05", "01/02/2006 15:04", "01/02/2006", // Azure Cost Management Date column
+}
+
+// parseStartTime parses a usage start timestamp, returning the zero time when it cannot
+func parseStartTime(value string) time.Time {
+	value = strings.TrimSpace(value)
+	for _, layout := range startTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// parseStartTimeIn parses a usage start timestamp like parseStartTime, reading timestamps
+// without an offset as local time in loc, and returns it in UTC
+func parseStartTimeIn(value string, loc *time.Location) time.Time {
+	value = strings.TrimSpace(value)
+	for _, layout := range startTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t.UTC()
+		}
+	}
+	return time.Time{}
 }
 
 // Service type mappers
@@ -0,0 +1,66 @@
+package billing
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDetectColumnIndices(t *testing.T) {
+	headers := []string{"Region", " instance_hours ", "\ufeffService", "period", "ResourceId"}
+	got, err := detectColumnIndices(headers, billingColumns)
+	if err != nil {
+		t.Fatalf("detectColumnIndices: %v", err)
+	}
+
+	want := map[string]int{"region": 0, "instanceHours": 1, "service": 2, "period": 3, "resourceId": 4}
+	for field, i := range want {
+		if got[field] != i {
+			t.Errorf("%s column = %d, want %d", field, got[field], i)
+		}
+	}
+}
+
+func TestDetectColumnIndicesMissingColumn(t *testing.T) {
+	_, err := detectColumnIndices([]string{"service", "resourceId", "period", "region"}, billingColumns)
+	if err == nil {
+		t.Fatal("expected an error for the missing instance-hours column")
+	}
+	for _, want := range []string{"instanceHours", "found headers: service, resourceId, period, region"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+}
+
+func TestParseBillingFileShuffledColumns(t *testing.T) {
+	tests := []struct {
+		provider string
+		file     string
+		wantType string
+		wantID   string
+		hours    float64
+		region   string
+	}{
+		{"aws", "testdata/aws-shuffled.csv", "VM", "i-1", 720, "us-east-1"},
+		{"azure", "testdata/azure-shuffled.csv", "VM", "vm-1", 744, "eastus"},
+		{"gcp", "testdata/gcp-shuffled.csv", "VM", "instance-1", 744, "us-central1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.provider, func(t *testing.T) {
+			records, err := ParseBillingFile(tt.file, tt.provider, ParseOptions{})
+			if err != nil {
+				t.Fatalf("ParseBillingFile: %v", err)
+			}
+			if len(records) != 2 {
+				t.Fatalf("got %d records, want 2", len(records))
+			}
+
+			r := records[0]
+			if r.ResourceType != tt.wantType || r.ResourceID != tt.wantID || r.InstanceHours != tt.hours ||
+				r.Region != tt.region || r.TimePeriod != "2024-01" {
+				t.Errorf("first record = %+v, want %s %s %.0fh in %s for 2024-01", r, tt.wantType, tt.wantID, tt.hours, tt.region)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package billing
+
+import (
+	"context"
+
+	"golang.org/x/time/rate"
+)
+
+// RateLimiter spaces out provider API requests. A nil *RateLimiter does not limit.
+type RateLimiter struct {
+	limiter *rate.Limiter
+}
+
+// NewRateLimiter returns a limiter allowing requestsPerSecond requests per second with a
+// burst of one, or nil (no limit) when requestsPerSecond is not positive
+func NewRateLimiter(requestsPerSecond float64) *RateLimiter {
+	if requestsPerSecond <= 0 {
+		return nil
+	}
+	return &RateLimiter{limiter: rate.NewLimiter(rate.Limit(requestsPerSecond), 1)}
+}
+
+// Wait blocks until the next request may be sent or ctx is done
+func (r *RateLimiter) Wait(ctx context.Context) error {
+	if r == nil {
+		return ctx.Err()
+	}
+	return r.limiter.Wait(ctx)
+}
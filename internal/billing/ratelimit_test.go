@@ -0,0 +1,63 @@
+package billing
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestNewRateLimiterUnlimited(t *testing.T) {
+	for _, requestsPerSecond := range []float64{0, -1} {
+		if limiter := NewRateLimiter(requestsPerSecond); limiter != nil {
+			t.Errorf("NewRateLimiter(%v) = %v, want nil (no limit)", requestsPerSecond, limiter)
+		}
+	}
+
+	var limiter *RateLimiter
+	start := time.Now()
+	for i := 0; i < 100; i++ {
+		if err := limiter.Wait(context.Background()); err != nil {
+			t.Fatalf("Wait: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("a nil limiter took %v for 100 waits, want no delay", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := limiter.Wait(ctx); err != context.Canceled {
+		t.Errorf("Wait on a cancelled context = %v, want context.Canceled", err)
+	}
+}
+
+func TestRateLimiterWait(t *testing.T) {
+	limiter := NewRateLimiter(20) // one request every 50ms
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if err := limiter.Wait(context.Background()); err != nil {
+			t.Fatalf("Wait: %v", err)
+		}
+	}
+	// The first request goes straight through; the next three wait 50ms each
+	if elapsed := time.Since(start); elapsed < 140*time.Millisecond {
+		t.Errorf("4 waits at 20/s took %v, want at least 150ms", elapsed)
+	}
+}
+
+func TestRateLimiterWaitCancelled(t *testing.T) {
+	limiter := NewRateLimiter(0.1) // one request every 10s
+	if err := limiter.Wait(context.Background()); err != nil {
+		t.Fatalf("first Wait: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := limiter.Wait(ctx); err == nil {
+		t.Error("Wait returned nil, want an error once the context cannot outlast the limit")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Wait took %v, want it to give up without waiting out the limit", elapsed)
+	}
+}
@@ -0,0 +1,41 @@
+package billing
+
+import (
+	"fmt"
+)
+
+// SplitCSVByType reads a provider's billing CSV and groups its raw data rows by the
+// resource type the parser assigns them, so the original columns are kept. It returns the
+// header row and the rows per type; rows the parser discards are left out. Stdin and GCP
+// JSON exports are not supported since the file is read twice.
+func SplitCSVByType(filePath, cloudProvider string, opts ParseOptions) ([]string, map[string][][]string, error) {
+	if filePath == StdinPath {
+		return nil, nil, fmt.Errorf("splitting billing data from stdin is not supported")
+	}
+	if cloudProvider == "gcp" && opts.GCPFormat == "json" {
+		return nil, nil, fmt.Errorf("splitting GCP JSON billing exports is not supported")
+	}
+
+	records, err := ParseBillingFile(filePath, cloudProvider, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	rows, err := readBillingCSV(filePath, cloudProvider, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil, fmt.Errorf("%s billing file is empty", cloudProvider)
+	}
+
+	// Each record's SourceRow is its CSV row index in rows
+	byType := make(map[string][][]string)
+	for _, record := range records {
+		if record.SourceRow <= 0 || record.SourceRow >= len(rows) {
+			continue
+		}
+		byType[record.ResourceType] = append(byType[record.ResourceType], rows[record.SourceRow])
+	}
+
+	return rows[0], byType, nil
+}
@@ -0,0 +1,29 @@
+package billing
+
+import (
+	"io"
+	"testing"
+)
+
+func TestParseBillingFileFromStdin(t *testing.T) {
+	reader, writer := io.Pipe()
+	saved := stdin
+	stdin = reader
+	defer func() { stdin = saved }()
+
+	go func() {
+		io.WriteString(writer, "service,resourceId,instanceHours,period,region\n")
+		io.WriteString(writer, "EC2,i-1,720,2024-01,us-east-1\n")
+		io.WriteString(writer, "EC2,i-2,360,2024-01,us-east-1\n")
+		io.WriteString(writer, "RDS,db-1,744,2024-01,us-east-1\n")
+		writer.Close()
+	}()
+
+	records, err := ParseBillingFile(StdinPath, "aws", ParseOptions{})
+	if err != nil {
+		t.Fatalf("ParseBillingFile: %v", err)
+	}
+	if len(records) != 3 {
+		t.Errorf("got %d records from stdin, want 3", len(records))
+	}
+}
@@ -0,0 +1,75 @@
+package billing
+
+import (
+	"github.com/ozwilder/CloudCostCalaCLI/internal/models"
+)
+
+// DefaultBatchSize is the number of records StreamBillingFile passes to flush at a time
+// when no batch size is given
+const DefaultBatchSize = 1000
+
+// StreamBillingFile reads a billing file like ParseBillingFile but passes its records to
+// flush in batches of batchSize (DefaultBatchSize when below 1) instead of returning them,
+// so memory stays bounded by the batch rather than the file. CSV files are read one row
+// at a time; GCP JSON exports are decoded whole before batching. The batch slice is reused
+// after flush returns, and a flush error stops parsing.
+func StreamBillingFile(filePath, cloudProvider string, opts ParseOptions, batchSize int,
+	flush func([]models.BillingRecord) error) error {
+	stream, err := providerStream(cloudProvider, opts)
+	if err != nil {
+		return err
+	}
+
+	batches := newRecordBatcher(batchSize, opts.Transform, flush)
+	if err := stream(filePath, opts, batches.add); err != nil {
+		return err
+	}
+	return batches.flush()
+}
+
+// recordBatcher collects records and passes them on in transformed batches
+type recordBatcher struct {
+	batch     []models.BillingRecord
+	size      int
+	transform *Transform
+	emit      func([]models.BillingRecord) error
+}
+
+// newRecordBatcher returns a batcher passing batches of size (DefaultBatchSize when below 1)
+// to emit, after applying transform when it is not nil
+func newRecordBatcher(size int, transform *Transform, emit func([]models.BillingRecord) error) *recordBatcher {
+	if size < 1 {
+		size = DefaultBatchSize
+	}
+	return &recordBatcher{
+		batch:     make([]models.BillingRecord, 0, size),
+		size:      size,
+		transform: transform,
+		emit:      emit,
+	}
+}
+
+// add appends a record, emitting the batch once it is full
+func (b *recordBatcher) add(record models.BillingRecord) error {
+	b.batch = append(b.batch, record)
+	if len(b.batch) < b.size {
+		return nil
+	}
+	return b.flush()
+}
+
+// flush emits the pending records, if any, and empties the batch for reuse
+func (b *recordBatcher) flush() error {
+	if len(b.batch) == 0 {
+		return nil
+	}
+	if b.transform != nil {
+		if err := b.transform.Apply(b.batch); err != nil {
+			return err
+		}
+	}
+	err := b.emit(b.batch)
+	clear(b.batch)
+	b.batch = b.batch[:0]
+	return err
+}
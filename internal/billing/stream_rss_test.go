@@ -0,0 +1,85 @@
+//go:build linux
+
+package billing
+
+import (
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+	"testing"
+
+	"github.com/ozwilder/CloudCostCalaCLI/internal/models"
+)
+
+// Environment variables that turn TestStreamRSSHelper into a benchmark child process
+const (
+	rssHelperFileEnv  = "CLOUDCOSTCALA_RSS_HELPER_FILE"
+	rssHelperBatchEnv = "CLOUDCOSTCALA_RSS_HELPER_BATCH"
+)
+
+// TestStreamRSSHelper normalizes a billing file in a child process started by
+// BenchmarkStreamBillingFilePeakRSS; a batch size of -1 parses the whole file at once
+func TestStreamRSSHelper(t *testing.T) {
+	path := os.Getenv(rssHelperFileEnv)
+	if path == "" {
+		t.Skip("only runs as a benchmark child process")
+	}
+	batchSize, err := strconv.Atoi(os.Getenv(rssHelperBatchEnv))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	acc := NewInstanceHoursAccumulator(nil)
+	if batchSize < 0 {
+		records, err := ParseBillingFile(path, "aws", ParseOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		acc.Add(records)
+	} else {
+		err := StreamBillingFile(path, "aws", ParseOptions{}, batchSize, func(batch []models.BillingRecord) error {
+			acc.Add(batch)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(acc.Normalize("2024-01")) == 0 {
+		t.Fatal("no instance-hours normalized")
+	}
+}
+
+// BenchmarkStreamBillingFilePeakRSS reports the peak RSS of normalizing a 200k-row AWS
+// billing file per batch size. Each run is a fresh child process, since a process's peak
+// RSS never goes down and would otherwise carry over between sub-benchmarks.
+func BenchmarkStreamBillingFilePeakRSS(b *testing.B) {
+	path := writeAWSBillingCSV(b, 200000)
+
+	for _, bench := range []struct {
+		name      string
+		batchSize int
+	}{
+		{"batch-100", 100},
+		{"batch-1000", 1000},
+		{"batch-10000", 10000},
+		{"parse-all", -1},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			var peak int64
+			for i := 0; i < b.N; i++ {
+				cmd := exec.Command(os.Args[0], "-test.run=^TestStreamRSSHelper$")
+				cmd.Env = append(os.Environ(),
+					rssHelperFileEnv+"="+path,
+					rssHelperBatchEnv+"="+strconv.Itoa(bench.batchSize))
+				if out, err := cmd.CombinedOutput(); err != nil {
+					b.Fatalf("helper process: %v\n%s", err, out)
+				}
+				usage := cmd.ProcessState.SysUsage().(*syscall.Rusage)
+				peak = max(peak, usage.Maxrss*1024) // Maxrss is in KiB on Linux
+			}
+			b.ReportMetric(float64(peak), "peak-rss-B")
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package billing
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ozwilder/CloudCostCalaCLI/internal/models"
+)
+
+// writeAWSBillingCSV writes an AWS billing CSV with the given number of data rows
+func writeAWSBillingCSV(tb testing.TB, rows int) string {
+	tb.Helper()
+	path := filepath.Join(tb.TempDir(), "aws.csv")
+	file, err := os.Create(path)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	defer file.Close()
+
+	services := []string{"EC2", "RDS", "EKS", "Lambda", "S3"}
+	w := bufio.NewWriter(file)
+	fmt.Fprintln(w, "service,resourceId,instanceHours,period,region")
+	for i := 0; i < rows; i++ {
+		fmt.Fprintf(w, "%s,r-%d,%d.5,2024-01,us-east-1\n", services[i%len(services)], i, i%744)
+	}
+	if err := w.Flush(); err != nil {
+		tb.Fatal(err)
+	}
+	return path
+}
+
+func TestStreamBillingFileMatchesParse(t *testing.T) {
+	path := writeAWSBillingCSV(t, 25)
+	want, err := ParseBillingFile(path, "aws", ParseOptions{})
+	if err != nil {
+		t.Fatalf("ParseBillingFile: %v", err)
+	}
+
+	tests := []struct {
+		batchSize int
+		batches   []int
+	}{
+		{batchSize: 10, batches: []int{10, 10, 5}},
+		{batchSize: 25, batches: []int{25}},
+		{batchSize: 0, batches: []int{25}}, // DefaultBatchSize
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("batch-%d", tt.batchSize), func(t *testing.T) {
+			var got []models.BillingRecord
+			var batches []int
+			err := StreamBillingFile(path, "aws", ParseOptions{}, tt.batchSize, func(batch []models.BillingRecord) error {
+				got = append(got, batch...)
+				batches = append(batches, len(batch))
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("StreamBillingFile: %v", err)
+			}
+			if !reflect.DeepEqual(batches, tt.batches) {
+				t.Errorf("batch sizes = %v, want %v", batches, tt.batches)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("streamed records differ from ParseBillingFile")
+			}
+		})
+	}
+}
+
+func TestStreamBillingFileStopsOnFlushError(t *testing.T) {
+	path := writeAWSBillingCSV(t, 25)
+	errStop := errors.New("stop")
+
+	calls := 0
+	err := StreamBillingFile(path, "aws", ParseOptions{}, 10, func([]models.BillingRecord) error {
+		calls++
+		return errStop
+	})
+	if !errors.Is(err, errStop) {
+		t.Errorf("err = %v, want %v", err, errStop)
+	}
+	if calls != 1 {
+		t.Errorf("flush called %d times, want 1", calls)
+	}
+}
+
+func TestInstanceHoursAccumulatorMatchesNormalize(t *testing.T) {
+	records := syntheticRecords(1000)
+	coefficients := map[string]float64{"": 0.5}
+	want := NormalizeToInstanceHours(records, "2024-01", coefficients)
+
+	acc := NewInstanceHoursAccumulator(coefficients)
+	for start := 0; start < len(records); start += 300 {
+		acc.Add(records[start:min(start+300, len(records))])
+	}
+	if got := acc.Normalize("2024-01"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Normalize() = %v, want %v", got, want)
+	}
+}
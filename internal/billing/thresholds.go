@@ -0,0 +1,75 @@
+package billing
+
+import (
+	"github.com/ozwilder/CloudCostCalaCLI/internal/config"
+	"github.com/ozwilder/CloudCostCalaCLI/internal/models"
+)
+
+// ThresholdViolation describes a synthetic-unit total that exceeded its configured limit
+type ThresholdViolation struct {
+	AssetType string
+	Computed  int
+	Limit     int
+}
+
+// CheckThresholds compares synthetic units against per-type and total limits.
+// A limit of zero means no limit; reaching a limit exactly is not a violation.
+func CheckThresholds(aggregated []models.AggregatedOutput, limits config.ThresholdsConfig) []ThresholdViolation {
+	violations := make([]ThresholdViolation, 0)
+	totalUnits := 0
+
+	for _, a := range aggregated {
+		totalUnits += a.SyntheticUnits
+
+		limit, exists := limits.Limits[a.AssetType]
+		if exists && limit > 0 && a.SyntheticUnits > limit {
+			violations = append(violations, ThresholdViolation{
+				AssetType: a.AssetType,
+				Computed:  a.SyntheticUnits,
+				Limit:     limit,
+			})
+		}
+	}
+
+	if limits.TotalUnitsLimit > 0 && totalUnits > limits.TotalUnitsLimit {
+		violations = append(violations, ThresholdViolation{
+			AssetType: "TOTAL",
+			Computed:  totalUnits,
+			Limit:     limits.TotalUnitsLimit,
+		})
+	}
+
+	return violations
+}
+
+// ShareViolation describes an asset type whose share of total synthetic units exceeded a limit
+type ShareViolation struct {
+	AssetType string
+	Share     float64 // Fraction of total synthetic units, 0-1
+}
+
+// CheckShareThreshold returns the asset types whose share of total synthetic units is
+// greater than maxShare (a fraction such as 0.60). A maxShare of zero or less checks nothing.
+func CheckShareThreshold(aggregated []models.AggregatedOutput, maxShare float64) []ShareViolation {
+	violations := make([]ShareViolation, 0)
+	if maxShare <= 0 {
+		return violations
+	}
+
+	totalUnits := 0
+	for _, a := range aggregated {
+		totalUnits += a.SyntheticUnits
+	}
+	if totalUnits == 0 {
+		return violations
+	}
+
+	for _, a := range aggregated {
+		share := float64(a.SyntheticUnits) / float64(totalUnits)
+		if share > maxShare {
+			violations = append(violations, ShareViolation{AssetType: a.AssetType, Share: share})
+		}
+	}
+
+	return violations
+}
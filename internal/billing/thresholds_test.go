@@ -0,0 +1,57 @@
+package billing
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ozwilder/CloudCostCalaCLI/internal/config"
+	"github.com/ozwilder/CloudCostCalaCLI/internal/models"
+)
+
+func TestCheckThresholds(t *testing.T) {
+	aggregated := []models.AggregatedOutput{
+		{AssetType: "VM", SyntheticUnits: 120},
+		{AssetType: "Database", SyntheticUnits: 50},
+	}
+
+	tests := []struct {
+		name   string
+		limits config.ThresholdsConfig
+		want   []ThresholdViolation
+	}{
+		{
+			name:   "per-type limit exceeded",
+			limits: config.ThresholdsConfig{Limits: map[string]int{"VM": 100, "Database": 80}},
+			want:   []ThresholdViolation{{AssetType: "VM", Computed: 120, Limit: 100}},
+		},
+		{
+			name:   "total limit exceeded",
+			limits: config.ThresholdsConfig{TotalUnitsLimit: 150},
+			want:   []ThresholdViolation{{AssetType: "TOTAL", Computed: 170, Limit: 150}},
+		},
+		{
+			name:   "per-type limit equal to computed",
+			limits: config.ThresholdsConfig{Limits: map[string]int{"VM": 120}},
+			want:   []ThresholdViolation{},
+		},
+		{
+			name:   "total limit equal to computed",
+			limits: config.ThresholdsConfig{TotalUnitsLimit: 170},
+			want:   []ThresholdViolation{},
+		},
+		{
+			name:   "zero limits disable checks",
+			limits: config.ThresholdsConfig{Limits: map[string]int{"VM": 0}},
+			want:   []ThresholdViolation{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CheckThresholds(aggregated, tt.limits)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CheckThresholds() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
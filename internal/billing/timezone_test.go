@@ -0,0 +1,72 @@
+package billing
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	_ "time/tzdata" // US/Eastern without relying on the host's zoneinfo
+)
+
+func TestParseAzureBillingConvertsTimezoneToUTC(t *testing.T) {
+	tests := []struct {
+		name string
+		csv  string
+	}{
+		{
+			name: "start time column",
+			csv:  "service,resourceId,instanceHours,period,region,startTime\nVirtual Machines,vm-1,1,2024-01,eastus,2024-01-31 23:00:00\n",
+		},
+		{
+			name: "date column without seconds",
+			csv:  "service,resourceId,instanceHours,period,region,Date\nVirtual Machines,vm-1,1,2024-01,eastus,2024-01-31 23:00\n",
+		},
+		{
+			name: "US-style date column",
+			csv:  "service,resourceId,instanceHours,period,region,Date\nVirtual Machines,vm-1,1,2024-01,eastus,01/31/2024 23:00\n",
+		},
+	}
+
+	want := time.Date(2024, 2, 1, 4, 0, 0, 0, time.UTC)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "azure.csv")
+			if err := os.WriteFile(path, []byte(tt.csv), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			records, err := ParseBillingFile(path, "azure", ParseOptions{AzureTimezone: "US/Eastern"})
+			if err != nil {
+				t.Fatalf("ParseBillingFile: %v", err)
+			}
+			if len(records) != 1 {
+				t.Fatalf("got %d records, want 1", len(records))
+			}
+			if r := records[0]; !r.StartTime.Equal(want) || r.TimePeriod != "2024-02" {
+				t.Errorf("got start %v in period %s, want %v in 2024-02", r.StartTime, r.TimePeriod, want)
+			}
+		})
+	}
+}
+
+func TestParseAzureBillingWithoutTimezoneKeepsPeriod(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "azure.csv")
+	csv := "service,resourceId,instanceHours,period,region,Date\nVirtual Machines,vm-1,1,2024-01,eastus,2024-01-31 23:00\n"
+	if err := os.WriteFile(path, []byte(csv), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := ParseBillingFile(path, "azure", ParseOptions{})
+	if err != nil {
+		t.Fatalf("ParseBillingFile: %v", err)
+	}
+	if records[0].TimePeriod != "2024-01" {
+		t.Errorf("period = %s, want 2024-01 when no timezone is configured", records[0].TimePeriod)
+	}
+}
+
+func TestParseAzureBillingRejectsUnknownTimezone(t *testing.T) {
+	if _, err := ParseBillingFile("testdata/azure-shuffled.csv", "azure", ParseOptions{AzureTimezone: "Mars/Olympus"}); err == nil {
+		t.Error("expected an error for an unknown timezone")
+	}
+}
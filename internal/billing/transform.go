@@ -0,0 +1,152 @@
+package billing
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/ozwilder/CloudCostCalaCLI/internal/models"
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+)
+
+// Transform is a compiled Starlark script that rewrites billing records. The script defines
+// transform(record), which receives each record as a dict and either edits it in place or
+// returns a new dict:
+//
+//	def transform(record):
+//	    record["project"] = record["metadata"].get("Environment", record["project"])
+type Transform struct {
+	fn *starlark.Function
+}
+
+// LoadTransform compiles the transform script at filePath. Only Starlark (.star) scripts
+// are supported.
+func LoadTransform(filePath string) (*Transform, error) {
+	if ext := strings.ToLower(filepath.Ext(filePath)); ext != ".star" {
+		return nil, fmt.Errorf("unsupported transform script %q: expected a Starlark .star file", filePath)
+	}
+
+	thread := &starlark.Thread{Name: "load " + filePath}
+	globals, err := starlark.ExecFileOptions(&syntax.FileOptions{}, thread, filePath, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load transform script: %w", err)
+	}
+	globals.Freeze()
+
+	fn, ok := globals["transform"].(*starlark.Function)
+	if !ok {
+		return nil, fmt.Errorf("transform script %s does not define transform(record)", filePath)
+	}
+	return &Transform{fn: fn}, nil
+}
+
+// Apply runs the script on every record, updating the records in place. It is safe to call
+// from several goroutines.
+func (t *Transform) Apply(records []models.BillingRecord) error {
+	thread := &starlark.Thread{Name: "transform"}
+	for i := range records {
+		dict := recordToDict(records[i])
+		result, err := starlark.Call(thread, t.fn, starlark.Tuple{dict}, nil)
+		if err != nil {
+			return fmt.Errorf("transform failed for record %d (%s): %w", i, records[i].ResourceID, err)
+		}
+
+		if returned, ok := result.(*starlark.Dict); ok {
+			dict = returned
+		} else if result != starlark.None {
+			return fmt.Errorf("transform must return a dict or None, got %s", result.Type())
+		}
+		if err := dictToRecord(dict, &records[i]); err != nil {
+			return fmt.Errorf("transform record %d (%s): %w", i, records[i].ResourceID, err)
+		}
+	}
+	return nil
+}
+
+// recordToDict converts the editable fields of a record to a Starlark dict
+func recordToDict(record models.BillingRecord) *starlark.Dict {
+	metadata := starlark.NewDict(len(record.Metadata))
+	for key, value := range record.Metadata {
+		metadata.SetKey(starlark.String(key), starlark.String(value))
+	}
+
+	dict := starlark.NewDict(12)
+	dict.SetKey(starlark.String("provider"), starlark.String(record.Provider))
+	dict.SetKey(starlark.String("service_name"), starlark.String(record.ServiceName))
+	dict.SetKey(starlark.String("resource_type"), starlark.String(record.ResourceType))
+	dict.SetKey(starlark.String("resource_id"), starlark.String(record.ResourceID))
+	dict.SetKey(starlark.String("instance_hours"), starlark.Float(record.InstanceHours))
+	dict.SetKey(starlark.String("cost"), starlark.Float(record.Cost))
+	dict.SetKey(starlark.String("is_marketplace"), starlark.Bool(record.IsMarketplace))
+	dict.SetKey(starlark.String("time_period"), starlark.String(record.TimePeriod))
+	dict.SetKey(starlark.String("region"), starlark.String(record.Region))
+	dict.SetKey(starlark.String("project"), starlark.String(record.Project))
+	dict.SetKey(starlark.String("account_id"), starlark.String(record.AccountID))
+	dict.SetKey(starlark.String("metadata"), metadata)
+	return dict
+}
+
+// dictToRecord copies the fields of a transformed dict back onto record. Keys missing from
+// the dict leave their field unchanged.
+func dictToRecord(dict *starlark.Dict, record *models.BillingRecord) error {
+	stringFields := map[string]*string{
+		"provider":      &record.Provider,
+		"service_name":  &record.ServiceName,
+		"resource_type": &record.ResourceType,
+		"resource_id":   &record.ResourceID,
+		"time_period":   &record.TimePeriod,
+		"region":        &record.Region,
+		"project":       &record.Project,
+		"account_id":    &record.AccountID,
+	}
+	for key, field := range stringFields {
+		value, found, _ := dict.Get(starlark.String(key))
+		if !found {
+			continue
+		}
+		s, ok := starlark.AsString(value)
+		if !ok {
+			return fmt.Errorf("%s must be a string, got %s", key, value.Type())
+		}
+		*field = s
+	}
+
+	floatFields := map[string]*float64{
+		"instance_hours": &record.InstanceHours,
+		"cost":           &record.Cost,
+	}
+	for key, field := range floatFields {
+		value, found, _ := dict.Get(starlark.String(key))
+		if !found {
+			continue
+		}
+		f, ok := starlark.AsFloat(value)
+		if !ok {
+			return fmt.Errorf("%s must be a number, got %s", key, value.Type())
+		}
+		*field = f
+	}
+
+	if value, found, _ := dict.Get(starlark.String("is_marketplace")); found {
+		record.IsMarketplace = bool(value.Truth())
+	}
+
+	if value, found, _ := dict.Get(starlark.String("metadata")); found {
+		metadata, ok := value.(*starlark.Dict)
+		if !ok {
+			return fmt.Errorf("metadata must be a dict, got %s", value.Type())
+		}
+		record.Metadata = make(map[string]string, metadata.Len())
+		for _, item := range metadata.Items() {
+			key, keyOK := starlark.AsString(item[0])
+			val, valOK := starlark.AsString(item[1])
+			if !keyOK || !valOK {
+				return fmt.Errorf("metadata keys and values must be strings")
+			}
+			record.Metadata[key] = val
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,38 @@
+package billing
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// WaitPollInterval is how often WaitForFiles checks for missing billing files
+const WaitPollInterval = 30 * time.Second
+
+// WaitForFiles polls until every path exists, checking every interval, and fails with the
+// paths still missing once timeout has passed. Empty paths and stdin are not waited for.
+func WaitForFiles(paths []string, interval, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		missing := make([]string, 0)
+		for _, path := range paths {
+			if path == "" || path == StdinPath {
+				continue
+			}
+			if _, err := os.Stat(path); err != nil {
+				missing = append(missing, path)
+			}
+		}
+		if len(missing) == 0 {
+			return nil
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return fmt.Errorf("billing files still missing after %s: %s", timeout, strings.Join(missing, ", "))
+		}
+		fmt.Printf("Waiting for billing files: %s\n", strings.Join(missing, ", "))
+		time.Sleep(min(interval, remaining))
+	}
+}
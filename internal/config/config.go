@@ -1,16 +1,40 @@
 package config
 
+// SyntheticUnitTier prices instances up to a cumulative average instance count.
+// UpTo of zero means the tier has no upper bound.
+type SyntheticUnitTier struct {
+	UpTo             float64 `json:"upTo"`
+	UnitsPerInstance int     `json:"unitsPerInstance"`
+}
+
+// Normalization strategies for SyntheticUnitRule.NormalizationStrategy
+const (
+	StrategyInstanceHours = "instance-hours" // Average instances per hour (default)
+	StrategySpend         = "spend"          // Average spend per hour
+)
+
+// Rounding modes for SyntheticUnitRule.RoundingMode
+const (
+	RoundingRound = "round" // Nearest, halves away from zero (default)
+	RoundingCeil  = "ceil"
+	RoundingFloor = "floor"
+)
+
 type SyntheticUnitRule struct {
-	UnitsPerInstance int `json:"unitsPerInstance"`
+	UnitsPerInstance      int                 `json:"unitsPerInstance"`
+	Tiers                 []SyntheticUnitTier `json:"tiers"`                 // Applied in order instead of UnitsPerInstance when set
+	NormalizationStrategy string              `json:"normalizationStrategy"` // instance-hours (default) or spend
+	RoundingMode          string              `json:"roundingMode"`          // round (default), ceil or floor
 }
 
 type SyntheticUnitsConfig struct {
-	Rules map[string]SyntheticUnitRule `json:"rules"`
+	Rules              map[string]SyntheticUnitRule `json:"rules"`
+	RegionCoefficients map[string]float64           `json:"regionCoefficients"` // Region → instance-hour weight, 1.0 when absent
 }
 
 type ProvidersConfig struct {
 	AWS struct {
-		Enabled bool   `json:"enabled"`
+		Enabled bool     `json:"enabled"`
 		Regions []string `json:"regions"`
 	} `json:"aws"`
 	Azure struct {
@@ -21,34 +45,159 @@ type ProvidersConfig struct {
 	} `json:"gcp"`
 }
 
+// TimezoneConfig names the IANA time zone (e.g. "US/Eastern") of timestamps exported without
+// an offset. When set, timestamps are converted to UTC before assigning billing periods.
+type TimezoneConfig struct {
+	Timezone string `json:"timezone"`
+}
+
+// KafkaConfig names a Kafka topic to read a provider's billing records from instead of a
+// file. Messages hold one record each, as JSON or Avro.
+type KafkaConfig struct {
+	Brokers            []string `json:"brokers"`
+	Topic              string   `json:"topic"`
+	ConsumerGroup      string   `json:"consumerGroup"`
+	Format             string   `json:"format"`             // json (default), avro or avro-confluent (schema registry wire format)
+	AvroSchemaFile     string   `json:"avroSchemaFile"`     // Record schema for the avro formats
+	IdleTimeoutSeconds int      `json:"idleTimeoutSeconds"` // Stop reading once the topic is idle this long (default 10)
+}
+
+// BillingConfig holds per-provider billing file settings. Period overrides the detected
+// billing period for that provider's normalization (YYYY-MM, or YYYY-MM-DD/YYYY-MM-DD).
 type BillingConfig struct {
 	AWS struct {
-		FilePath string `json:"filePath"`
-		Format   string `json:"format"`
-		Period   string `json:"period"`
+		FilePath             string      `json:"filePath"` // Overridden by CCC_AWS_BILLING_FILE
+		Format               string      `json:"format"`
+		Period               string      `json:"period"`
+		SkipRows             int         `json:"skipRows"`             // Informational rows before the CSV header
+		APIRequestsPerSecond float64     `json:"apiRequestsPerSecond"` // Rate limit for AWS API requests (0 means unlimited)
+		Kafka                KafkaConfig `json:"kafka"`
 	} `json:"aws"`
 	Azure struct {
-		FilePath string `json:"filePath"`
-		Format   string `json:"format"`
-		Period   string `json:"period"`
+		FilePath             string      `json:"filePath"` // Overridden by CCC_AZURE_BILLING_FILE
+		Format               string      `json:"format"`
+		Period               string      `json:"period"`
+		SkipRows             int         `json:"skipRows"`             // Informational rows before the CSV header
+		APIRequestsPerSecond float64     `json:"apiRequestsPerSecond"` // Rate limit for Azure Monitor requests (0 means unlimited)
+		Kafka                KafkaConfig `json:"kafka"`
+		TimezoneConfig
 	} `json:"azure"`
 	GCP struct {
-		FilePath string `json:"filePath"`
-		Format   string `json:"format"`
-		Period   string `json:"period"`
+		FilePath             string      `json:"filePath"` // Overridden by CCC_GCP_BILLING_FILE
+		Format               string      `json:"format"`
+		Period               string      `json:"period"`
+		SkipRows             int         `json:"skipRows"`             // Informational rows before the CSV header
+		APIRequestsPerSecond float64     `json:"apiRequestsPerSecond"` // Rate limit for GCP API requests (0 means unlimited)
+		Kafka                KafkaConfig `json:"kafka"`
 	} `json:"gcp"`
 }
 
 type OutputConfig struct {
-	Format                   string `json:"format"`
-	Filename                 string `json:"filename"`
-	IncludeEphemeralResources bool  `json:"includeEphemeralResources"`
-	IncludeBillingMetrics    bool  `json:"includeBillingMetrics"`
+	Format                    string `json:"format"`                    // Overridden by CCC_OUTPUT_FORMAT
+	Filename                  string `json:"filename"`                  // Overridden by CCC_OUTPUT_FILE
+	OutputFilenameTemplate    string `json:"outputFilenameTemplate"`    // text/template with Period, Timestamp, Provider; overrides -output
+	IncludeEphemeralResources bool   `json:"includeEphemeralResources"` // Deprecated: has no effect
+	IncludeBillingMetrics     bool   `json:"includeBillingMetrics"`     // Deprecated: has no effect
+}
+
+type ThresholdsConfig struct {
+	Limits          map[string]int `json:"limits"` // asset type -> max synthetic units
+	TotalUnitsLimit int            `json:"totalUnitsLimit"`
+}
+
+type VPCGroupConfig struct {
+	Groups map[string]string `json:"groups"` // VPC/VNet ID -> network group name
+}
+
+type WorkflowStep struct {
+	Name       string   `json:"name"`
+	Type       string   `json:"type"` // write-excel, write-json, notify-slack, archive
+	DependsOn  []string `json:"dependsOn"`
+	Path       string   `json:"path"`       // Output path for write-excel, write-json and archive
+	WebhookURL string   `json:"webhookUrl"` // Slack incoming webhook for notify-slack
+	Files      []string `json:"files"`      // Files to archive (defaults to the main output file)
+}
+
+type WorkflowConfig struct {
+	Steps []WorkflowStep `json:"steps"`
+}
+
+type ConversionTableConfig struct {
+	Systems map[string]float64 `json:"systems"` // unit system name -> multiplier per synthetic unit
+}
+
+type PricingConfig struct {
+	CostPerSyntheticUnit float64 `json:"costPerSyntheticUnit"`
+	Currency             string  `json:"currency"` // Defaults to USD
+}
+
+// IndustryBaseline describes the industry distribution of synthetic units for an asset type
+type IndustryBaseline struct {
+	AssetType string `json:"assetType"`
+	P50Units  int    `json:"p50Units"`
+	P95Units  int    `json:"p95Units"`
+}
+
+// InventoryConfig controls how the asset inventory file is read. CSVColumns maps asset
+// fields (id, type, name, cloud, project, currentInstanceCount, deploymentCount) to CSV
+// headers for -inventory-format csv; unmapped fields use their snake_case name.
+type InventoryConfig struct {
+	CSVColumns map[string]string `json:"csvColumns"`
+}
+
+// FieldMaskingConfig replaces billing record fields that may hold personal data (IAM user
+// names, email addresses) with MASKED-<sha256 prefix> right after parsing
+type FieldMaskingConfig struct {
+	MaskResourceID bool `json:"maskResourceId"`
+	MaskProject    bool `json:"maskProject"`
+}
+
+// TelemetryConfig controls anonymous usage analytics. Collection is not implemented yet;
+// the settings are reserved so existing configs keep working once it is.
+type TelemetryConfig struct {
+	Enabled  bool   `json:"enabled"`
+	Endpoint string `json:"endpoint"`
 }
 
 type Config struct {
-	Providers      ProvidersConfig      `json:"providers"`
-	Billing        BillingConfig        `json:"billing"`
-	SyntheticUnits SyntheticUnitsConfig `json:"syntheticUnits"`
-	Output         OutputConfig         `json:"output"`
+	Providers       ProvidersConfig       `json:"providers"`
+	Billing         BillingConfig         `json:"billing"`
+	SyntheticUnits  SyntheticUnitsConfig  `json:"syntheticUnits"`
+	Output          OutputConfig          `json:"output"`
+	Thresholds      ThresholdsConfig      `json:"thresholds"`
+	VPCGroups       VPCGroupConfig        `json:"vpcGroups"`
+	Workflow        WorkflowConfig        `json:"workflow"`
+	ConversionTable ConversionTableConfig `json:"conversionTable"`
+	BudgetedUnits   map[string]int        `json:"budgetedUnits"` // asset type -> budgeted synthetic units
+	Pricing         PricingConfig         `json:"pricing"`
+	Telemetry       TelemetryConfig       `json:"telemetry"`
+	FieldMasking    FieldMaskingConfig    `json:"fieldMasking"`
+	Inventory       InventoryConfig       `json:"inventory"`
+}
+
+// SkipRows returns the configured rows to skip before the header per provider key (aws, azure, gcp)
+func (b BillingConfig) SkipRows() map[string]int {
+	return map[string]int{
+		"aws":   b.AWS.SkipRows,
+		"azure": b.Azure.SkipRows,
+		"gcp":   b.GCP.SkipRows,
+	}
+}
+
+// RequestsPerSecond returns the configured API rate limit per provider name (AWS, Azure, GCP)
+func (b BillingConfig) RequestsPerSecond() map[string]float64 {
+	return map[string]float64{
+		"AWS":   b.AWS.APIRequestsPerSecond,
+		"Azure": b.Azure.APIRequestsPerSecond,
+		"GCP":   b.GCP.APIRequestsPerSecond,
+	}
+}
+
+// Periods returns the configured billing period per provider name (AWS, Azure, GCP)
+func (b BillingConfig) Periods() map[string]string {
+	return map[string]string{
+		"AWS":   b.AWS.Period,
+		"Azure": b.Azure.Period,
+		"GCP":   b.GCP.Period,
+	}
 }
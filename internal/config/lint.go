@@ -0,0 +1,61 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// LintWarning describes a deprecated config field and how to migrate away from it
+type LintWarning struct {
+	Field   string
+	Line    int
+	Message string
+}
+
+// String formats the warning for console output
+func (w LintWarning) String() string {
+	return fmt.Sprintf("line %d: %s: %s", w.Line, w.Field, w.Message)
+}
+
+// deprecatedField is a config path that is still accepted but no longer has an effect
+type deprecatedField struct {
+	Path      string // Dotted JSON path; * matches any single segment
+	Migration string
+}
+
+// deprecatedFields lists known deprecated config fields with migration instructions
+var deprecatedFields = []deprecatedField{
+	{
+		Path:      "output.includeEphemeralResources",
+		Migration: "has no effect; ephemeral types are always reported. Remove it, and use -ephemeral-only to report only ephemeral types",
+	},
+	{
+		Path:      "output.includeBillingMetrics",
+		Migration: "has no effect; billing metrics are always reported. Remove it, and use -excel-include-raw or -raw-output for per-record data",
+	},
+}
+
+// LintConfig reports every deprecated field set in raw JSON config data, with its line
+// and migration instructions. Malformed JSON is left to ValidateSchema.
+func LintConfig(raw json.RawMessage) []LintWarning {
+	values, err := scanValues(raw)
+	if err != nil {
+		return nil
+	}
+
+	warnings := make([]LintWarning, 0)
+	for _, v := range values {
+		for _, field := range deprecatedFields {
+			if matchesPath(v.path, field.Path) {
+				warnings = append(warnings, LintWarning{
+					Field:   strings.Join(v.path, "."),
+					Line:    v.line,
+					Message: "deprecated: " + field.Migration,
+				})
+			}
+		}
+	}
+
+	return warnings
+}
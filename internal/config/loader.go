@@ -12,11 +12,17 @@ func LoadConfig(filePath string) (*Config, error) {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
+	if err := schemaErrorsToError(ValidateSchema(data)); err != nil {
+		return nil, fmt.Errorf("invalid config file %s:\n%w", filePath, err)
+	}
+
 	var cfg Config
 	if err := json.Unmarshal(data, &cfg); err != nil {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
 
+	ApplyEnvOverrides(&cfg)
+
 	// Validate required rules
 	if cfg.SyntheticUnits.Rules == nil {
 		cfg.SyntheticUnits.Rules = make(map[string]SyntheticUnitRule)
@@ -24,3 +30,71 @@ func LoadConfig(filePath string) (*Config, error) {
 
 	return &cfg, nil
 }
+
+// ApplyEnvOverrides replaces config values with any well-known CCC_* environment variables that are set
+func ApplyEnvOverrides(cfg *Config) {
+	overrides := map[string]*string{
+		"CCC_AWS_BILLING_FILE":   &cfg.Billing.AWS.FilePath,
+		"CCC_AZURE_BILLING_FILE": &cfg.Billing.Azure.FilePath,
+		"CCC_GCP_BILLING_FILE":   &cfg.Billing.GCP.FilePath,
+		"CCC_OUTPUT_FILE":        &cfg.Output.Filename,
+		"CCC_OUTPUT_FORMAT":      &cfg.Output.Format,
+	}
+
+	for name, field := range overrides {
+		if value, ok := os.LookupEnv(name); ok && value != "" {
+			*field = value
+		}
+	}
+}
+
+// LoadRegionalPricing reads a JSON map of region to instance-hour coefficient
+func LoadRegionalPricing(filePath string) (map[string]float64, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read regional pricing file: %w", err)
+	}
+
+	var coefficients map[string]float64
+	if err := json.Unmarshal(data, &coefficients); err != nil {
+		return nil, fmt.Errorf("failed to parse regional pricing file: %w", err)
+	}
+
+	for region, coefficient := range coefficients {
+		if coefficient < 0 {
+			return nil, fmt.Errorf("regional pricing coefficient for %s is negative: %g", region, coefficient)
+		}
+	}
+
+	return coefficients, nil
+}
+
+// LoadAccountMap reads a JSON object mapping account IDs to friendly names
+func LoadAccountMap(filePath string) (map[string]string, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read account map file: %w", err)
+	}
+
+	var names map[string]string
+	if err := json.Unmarshal(data, &names); err != nil {
+		return nil, fmt.Errorf("failed to parse account map file: %w", err)
+	}
+
+	return names, nil
+}
+
+// LoadIndustryBaselines reads a JSON array of industry baselines
+func LoadIndustryBaselines(filePath string) ([]IndustryBaseline, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read benchmark file: %w", err)
+	}
+
+	var baselines []IndustryBaseline
+	if err := json.Unmarshal(data, &baselines); err != nil {
+		return nil, fmt.Errorf("failed to parse benchmark file: %w", err)
+	}
+
+	return baselines, nil
+}
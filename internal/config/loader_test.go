@@ -0,0 +1,64 @@
+package config
+
+import "testing"
+
+func TestApplyEnvOverrides(t *testing.T) {
+	tests := []struct {
+		env   string
+		field func(cfg *Config) string
+	}{
+		{"CCC_AWS_BILLING_FILE", func(cfg *Config) string { return cfg.Billing.AWS.FilePath }},
+		{"CCC_AZURE_BILLING_FILE", func(cfg *Config) string { return cfg.Billing.Azure.FilePath }},
+		{"CCC_GCP_BILLING_FILE", func(cfg *Config) string { return cfg.Billing.GCP.FilePath }},
+		{"CCC_OUTPUT_FILE", func(cfg *Config) string { return cfg.Output.Filename }},
+		{"CCC_OUTPUT_FORMAT", func(cfg *Config) string { return cfg.Output.Format }},
+	}
+
+	// configured returns a config with every overridable field set from the file
+	configured := func() *Config {
+		cfg := &Config{}
+		cfg.Billing.AWS.FilePath = "config-aws.csv"
+		cfg.Billing.Azure.FilePath = "config-azure.csv"
+		cfg.Billing.GCP.FilePath = "config-gcp.csv"
+		cfg.Output.Filename = "config.xlsx"
+		cfg.Output.Format = "excel"
+		return cfg
+	}
+
+	// Blank every variable so the caller's environment cannot override anything
+	clearEnv := func(t *testing.T) {
+		for _, tt := range tests {
+			t.Setenv(tt.env, "")
+		}
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.env, func(t *testing.T) {
+			clearEnv(t)
+			want := configured()
+			t.Setenv(tt.env, "from-env")
+
+			cfg := configured()
+			ApplyEnvOverrides(cfg)
+			if got := tt.field(cfg); got != "from-env" {
+				t.Errorf("%s: field = %q, want %q", tt.env, got, "from-env")
+			}
+			// No other field changes
+			for _, other := range tests {
+				if other.env != tt.env && other.field(cfg) != other.field(want) {
+					t.Errorf("%s changed the %s field to %q", tt.env, other.env, other.field(cfg))
+				}
+			}
+		})
+
+		t.Run(tt.env+" empty", func(t *testing.T) {
+			clearEnv(t)
+
+			cfg := configured()
+			ApplyEnvOverrides(cfg)
+			if got, want := tt.field(cfg), tt.field(configured()); got != want {
+				t.Errorf("%s=\"\": field = %q, want config value %q", tt.env, got, want)
+			}
+		})
+	}
+}
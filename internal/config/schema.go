@@ -0,0 +1,240 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// SchemaError describes a config value that does not match the expected schema
+type SchemaError struct {
+	Field   string // Dotted JSON path, e.g. syntheticUnits.rules.VM.unitsPerInstance
+	Line    int    // 1-based line of the offending value (approximate)
+	Message string
+}
+
+// Error implements error
+func (e SchemaError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+	}
+	return fmt.Sprintf("line %d: %s: %s", e.Line, e.Field, e.Message)
+}
+
+// schemaPeriodPattern matches billing periods in YYYY-MM or YYYY-MM-DD/YYYY-MM-DD format
+var schemaPeriodPattern = regexp.MustCompile(`^\d{4}-(0[1-9]|1[0-2])$|^\d{4}-\d{2}-\d{2}/\d{4}-\d{2}-\d{2}$`)
+
+// Patterns for fields checked by ValidateSchema; * matches any single path segment
+var (
+	unitsPerInstancePaths = []string{
+		"syntheticUnits.rules.*.unitsPerInstance",
+		"syntheticUnits.rules.*.tiers.*.unitsPerInstance",
+	}
+	billingPeriodPath = "billing.*.period"
+	regionPath        = "providers.aws.regions.*"
+	outputFormatPath  = "output.format"
+)
+
+// schemaValue is a scalar JSON value found while scanning a config
+type schemaValue struct {
+	path  []string
+	value interface{}
+	line  int
+}
+
+// ValidateSchema checks raw JSON config data and reports each problem with the line it
+// occurs on: malformed JSON, non-positive or fractional unitsPerInstance values, unknown
+// output formats, malformed billing periods and empty AWS region entries.
+func ValidateSchema(data []byte) []SchemaError {
+	values, err := scanValues(data)
+	if err != nil {
+		return []SchemaError{syntaxSchemaError(data, err)}
+	}
+
+	errs := make([]SchemaError, 0)
+	for _, v := range values {
+		field := strings.Join(v.path, ".")
+		fail := func(format string, args ...interface{}) {
+			errs = append(errs, SchemaError{Field: field, Line: v.line, Message: fmt.Sprintf(format, args...)})
+		}
+
+		switch {
+		case matchesAny(v.path, unitsPerInstancePaths):
+			n, ok := v.value.(json.Number)
+			f, convErr := strconv.ParseFloat(string(n), 64)
+			if !ok || convErr != nil || f <= 0 || f != math.Trunc(f) {
+				fail("must be a positive integer, got %s", describeValue(v.value))
+			}
+		case matchesPath(v.path, outputFormatPath):
+			s, ok := v.value.(string)
+			if !ok || (s != "" && !contains(OutputFormats, s)) {
+				fail("must be one of: %s, got %s", strings.Join(OutputFormats, ", "), describeValue(v.value))
+			}
+		case matchesPath(v.path, billingPeriodPath):
+			s, ok := v.value.(string)
+			if !ok || (s != "" && !schemaPeriodPattern.MatchString(s)) {
+				fail("must be in YYYY-MM or YYYY-MM-DD/YYYY-MM-DD format, got %s", describeValue(v.value))
+			}
+		case matchesPath(v.path, regionPath):
+			s, ok := v.value.(string)
+			if !ok || strings.TrimSpace(s) == "" {
+				fail("must be a non-empty string, got %s", describeValue(v.value))
+			}
+		}
+	}
+
+	return errs
+}
+
+// schemaErrorsToError joins schema errors into a single error, or returns nil
+func schemaErrorsToError(errs []SchemaError) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	joined := make([]error, len(errs))
+	for i, e := range errs {
+		joined[i] = e
+	}
+	return errors.Join(joined...)
+}
+
+// scanValues walks the JSON token stream and returns every scalar value with its path and line
+func scanValues(data []byte) ([]schemaValue, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	type frame struct {
+		array     bool
+		index     int
+		key       string
+		expectKey bool
+	}
+	stack := make([]*frame, 0)
+	values := make([]schemaValue, 0)
+
+	currentPath := func() []string {
+		path := make([]string, 0, len(stack))
+		for _, fr := range stack {
+			if fr.array {
+				path = append(path, strconv.Itoa(fr.index))
+			} else {
+				path = append(path, fr.key)
+			}
+		}
+		return path
+	}
+	// advance moves the enclosing container past the value just read
+	advance := func() {
+		if len(stack) == 0 {
+			return
+		}
+		top := stack[len(stack)-1]
+		if top.array {
+			top.index++
+		} else {
+			top.expectKey = true
+		}
+	}
+
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if len(stack) > 0 {
+			top := stack[len(stack)-1]
+			if !top.array && top.expectKey {
+				if key, ok := tok.(string); ok {
+					top.key = key
+					top.expectKey = false
+					continue
+				}
+			}
+		}
+
+		switch t := tok.(type) {
+		case json.Delim:
+			switch t {
+			case '{':
+				stack = append(stack, &frame{expectKey: true})
+			case '[':
+				stack = append(stack, &frame{array: true})
+			case '}', ']':
+				stack = stack[:len(stack)-1]
+				advance()
+			}
+		default:
+			values = append(values, schemaValue{
+				path:  currentPath(),
+				value: t,
+				line:  lineAt(data, dec.InputOffset()),
+			})
+			advance()
+		}
+	}
+
+	return values, nil
+}
+
+// syntaxSchemaError converts a JSON decoding error into a SchemaError with its line
+func syntaxSchemaError(data []byte, err error) SchemaError {
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return SchemaError{Line: lineAt(data, syntaxErr.Offset), Message: syntaxErr.Error()}
+	}
+	return SchemaError{Line: lineAt(data, int64(len(data))), Message: err.Error()}
+}
+
+// lineAt returns the 1-based line number of a byte offset
+func lineAt(data []byte, offset int64) int {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	return bytes.Count(data[:offset], []byte("\n")) + 1
+}
+
+// matchesAny reports whether path matches any of the patterns
+func matchesAny(path []string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matchesPath(path, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesPath reports whether path matches a dotted pattern where * matches one segment
+func matchesPath(path []string, pattern string) bool {
+	segments := strings.Split(pattern, ".")
+	if len(segments) != len(path) {
+		return false
+	}
+	for i, segment := range segments {
+		if segment != "*" && segment != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// describeValue formats a JSON value for error messages
+func describeValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return strconv.Quote(v)
+	case nil:
+		return "null"
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,47 @@
+package config
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestValidateSchemaFixtures(t *testing.T) {
+	tests := []struct {
+		fixture string
+		field   string
+		line    int
+		message string
+	}{
+		{"schema-units-per-instance.json", "syntheticUnits.rules.Database.unitsPerInstance", 5, "must be a positive integer"},
+		{"schema-output-format.json", "output.format", 4, "must be one of: excel, json"},
+		{"schema-billing-period.json", "billing.gcp.period", 5, "must be in YYYY-MM"},
+		{"schema-aws-regions.json", "providers.aws.regions.1", 5, "must be a non-empty string"},
+		{"schema-syntax.json", "", 3, "invalid character"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			data, err := os.ReadFile("testdata/" + tt.fixture)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			errs := ValidateSchema(data)
+			if len(errs) != 1 {
+				t.Fatalf("ValidateSchema() = %v, want exactly one error", errs)
+			}
+			got := errs[0]
+			if got.Field != tt.field || got.Line != tt.line || !strings.Contains(got.Message, tt.message) {
+				t.Errorf("got %+v, want field %q on line %d with message containing %q", got, tt.field, tt.line, tt.message)
+			}
+		})
+	}
+}
+
+func TestLoadConfigReportsSchemaErrors(t *testing.T) {
+	_, err := LoadConfig("testdata/schema-output-format.json")
+	if err == nil || !strings.Contains(err.Error(), "line 4: output.format") {
+		t.Errorf("LoadConfig() error = %v, want the output.format schema error", err)
+	}
+}
@@ -0,0 +1,101 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// OutputFormats lists the accepted values for output.format
+var OutputFormats = []string{"excel", "json"}
+
+// KafkaFormats lists the accepted values for billing.*.kafka.format
+var KafkaFormats = []string{"json", "avro", "avro-confluent"}
+
+// Validate checks a loaded config and returns every problem found
+func Validate(cfg *Config) []error {
+	errs := make([]error, 0)
+
+	providers := []struct {
+		name              string
+		enabled           bool
+		filePath          string
+		requestsPerSecond float64
+		kafka             KafkaConfig
+	}{
+		{"aws", cfg.Providers.AWS.Enabled, cfg.Billing.AWS.FilePath, cfg.Billing.AWS.APIRequestsPerSecond, cfg.Billing.AWS.Kafka},
+		{"azure", cfg.Providers.Azure.Enabled, cfg.Billing.Azure.FilePath, cfg.Billing.Azure.APIRequestsPerSecond,
+			cfg.Billing.Azure.Kafka},
+		{"gcp", cfg.Providers.GCP.Enabled, cfg.Billing.GCP.FilePath, cfg.Billing.GCP.APIRequestsPerSecond, cfg.Billing.GCP.Kafka},
+	}
+	for _, p := range providers {
+		if p.enabled && p.filePath == "" && p.kafka.Topic == "" {
+			errs = append(errs, fmt.Errorf("providers.%s is enabled but billing.%s.filePath is empty", p.name, p.name))
+		}
+		if p.requestsPerSecond < 0 {
+			errs = append(errs, fmt.Errorf("billing.%s.apiRequestsPerSecond must not be negative, got %v",
+				p.name, p.requestsPerSecond))
+		}
+		if p.kafka.Topic == "" {
+			continue
+		}
+		if len(p.kafka.Brokers) == 0 {
+			errs = append(errs, fmt.Errorf("billing.%s.kafka.topic is set but billing.%s.kafka.brokers is empty", p.name, p.name))
+		}
+		if format := p.kafka.Format; format != "" && !contains(KafkaFormats, format) {
+			errs = append(errs, fmt.Errorf("billing.%s.kafka.format %q is not one of: %s",
+				p.name, format, strings.Join(KafkaFormats, ", ")))
+		}
+		if strings.HasPrefix(p.kafka.Format, "avro") && p.kafka.AvroSchemaFile == "" {
+			errs = append(errs, fmt.Errorf("billing.%s.kafka.format is %s but billing.%s.kafka.avroSchemaFile is empty",
+				p.name, p.kafka.Format, p.name))
+		}
+	}
+
+	if len(cfg.SyntheticUnits.Rules) == 0 {
+		errs = append(errs, fmt.Errorf("syntheticUnits.rules is empty"))
+	}
+
+	for assetType, rule := range cfg.SyntheticUnits.Rules {
+		strategy := rule.NormalizationStrategy
+		if strategy != "" && strategy != StrategyInstanceHours && strategy != StrategySpend {
+			errs = append(errs, fmt.Errorf("syntheticUnits.rules.%s.normalizationStrategy %q is not one of: %s, %s",
+				assetType, strategy, StrategyInstanceHours, StrategySpend))
+		}
+
+		mode := rule.RoundingMode
+		if mode != "" && mode != RoundingRound && mode != RoundingCeil && mode != RoundingFloor {
+			errs = append(errs, fmt.Errorf("syntheticUnits.rules.%s.roundingMode %q is not one of: %s, %s, %s",
+				assetType, mode, RoundingRound, RoundingCeil, RoundingFloor))
+		}
+	}
+
+	if cfg.Output.Format != "" && !contains(OutputFormats, cfg.Output.Format) {
+		errs = append(errs, fmt.Errorf("output.format %q is not one of: %s",
+			cfg.Output.Format, strings.Join(OutputFormats, ", ")))
+	}
+
+	if cfg.Output.OutputFilenameTemplate != "" {
+		if _, err := template.New("filename").Parse(cfg.Output.OutputFilenameTemplate); err != nil {
+			errs = append(errs, fmt.Errorf("output.outputFilenameTemplate is not a valid template: %v", err))
+		}
+	}
+
+	if tz := cfg.Billing.Azure.Timezone; tz != "" {
+		if _, err := time.LoadLocation(tz); err != nil {
+			errs = append(errs, fmt.Errorf("billing.azure.timezone %q is not a known time zone", tz))
+		}
+	}
+
+	return errs
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+// validConfig returns a config that passes Validate
+func validConfig() *Config {
+	cfg := &Config{}
+	cfg.Providers.AWS.Enabled = true
+	cfg.Billing.AWS.FilePath = "aws.csv"
+	cfg.SyntheticUnits.Rules = map[string]SyntheticUnitRule{"VM": {UnitsPerInstance: 5}}
+	cfg.Output.Format = "excel"
+	return cfg
+}
+
+func TestValidateValidConfig(t *testing.T) {
+	if errs := Validate(validConfig()); len(errs) != 0 {
+		t.Errorf("Validate() = %v, want no errors", errs)
+	}
+}
+
+func TestValidateReportsEveryError(t *testing.T) {
+	cfg := validConfig()
+	cfg.Billing.AWS.FilePath = ""
+	cfg.Providers.GCP.Enabled = true
+	cfg.SyntheticUnits.Rules = nil
+	cfg.Output.Format = "pdf"
+
+	errs := Validate(cfg)
+	want := []string{
+		"providers.aws is enabled but billing.aws.filePath is empty",
+		"providers.gcp is enabled but billing.gcp.filePath is empty",
+		"syntheticUnits.rules is empty",
+		`output.format "pdf" is not one of`,
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("Validate() returned %d errors, want %d: %v", len(errs), len(want), errs)
+	}
+	for _, w := range want {
+		found := false
+		for _, err := range errs {
+			if strings.Contains(err.Error(), w) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("no error mentioning %q in %v", w, errs)
+		}
+	}
+}
+
+func TestValidateKafka(t *testing.T) {
+	cfg := validConfig()
+	cfg.Billing.AWS.FilePath = ""
+	cfg.Billing.AWS.Kafka = KafkaConfig{Brokers: []string{"localhost:9092"}, Topic: "aws-billing"}
+	if errs := Validate(cfg); len(errs) != 0 {
+		t.Errorf("Validate() = %v, want a Kafka topic to stand in for the billing file", errs)
+	}
+
+	cfg.Billing.AWS.Kafka = KafkaConfig{Topic: "aws-billing", Format: "avro"}
+	cfg.Billing.GCP.Kafka = KafkaConfig{Brokers: []string{"localhost:9092"}, Topic: "gcp-billing", Format: "xml"}
+	errs := Validate(cfg)
+	want := []string{
+		"billing.aws.kafka.topic is set but billing.aws.kafka.brokers is empty",
+		"billing.aws.kafka.format is avro but billing.aws.kafka.avroSchemaFile is empty",
+		`billing.gcp.kafka.format "xml" is not one of`,
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("Validate() returned %d errors, want %d: %v", len(errs), len(want), errs)
+	}
+	for i, w := range want {
+		if !strings.Contains(errs[i].Error(), w) {
+			t.Errorf("error %d = %v, want one mentioning %q", i, errs[i], w)
+		}
+	}
+}
+
+func TestValidateAPIRequestsPerSecond(t *testing.T) {
+	cfg := validConfig()
+	cfg.Billing.Azure.APIRequestsPerSecond = 5
+	cfg.Billing.GCP.APIRequestsPerSecond = -1
+	errs := Validate(cfg)
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "billing.gcp.apiRequestsPerSecond must not be negative") {
+		t.Errorf("Validate() = %v, want only the negative GCP rate limit reported", errs)
+	}
+	if got := cfg.Billing.RequestsPerSecond(); got["Azure"] != 5 || got["AWS"] != 0 {
+		t.Errorf("RequestsPerSecond() = %v, want Azure limited to 5", got)
+	}
+}
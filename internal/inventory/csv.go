@@ -0,0 +1,95 @@
+package inventory
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ozwilder/CloudCostCalaCLI/internal/models"
+)
+
+// defaultCSVColumns maps each asset field to its CSV header when inventory.csvColumns does
+// not override it
+var defaultCSVColumns = map[string]string{
+	"id":                   "id",
+	"type":                 "type",
+	"name":                 "name",
+	"cloud":                "cloud",
+	"project":              "project",
+	"currentInstanceCount": "current_instance_count",
+	"deploymentCount":      "deployment_count",
+}
+
+// loadCSV reads one asset per CSV row. The type column is required; the others are optional.
+func loadCSV(filePath string, columns map[string]string) ([]models.Asset, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open inventory file: %w", err)
+	}
+	defer file.Close()
+
+	rows, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read inventory CSV: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("inventory CSV is empty")
+	}
+
+	// Resolve each field's column index from the header
+	index := make(map[string]int)
+	for field, header := range defaultCSVColumns {
+		if mapped, ok := columns[field]; ok {
+			header = mapped
+		}
+		for i, h := range rows[0] {
+			if strings.EqualFold(strings.TrimSpace(h), header) {
+				index[field] = i
+				break
+			}
+		}
+	}
+	if _, ok := index["type"]; !ok {
+		return nil, fmt.Errorf("inventory CSV has no type column")
+	}
+
+	value := func(row []string, field string) string {
+		i, ok := index[field]
+		if !ok || i >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[i])
+	}
+
+	assets := make([]models.Asset, 0, len(rows)-1)
+	for i, row := range rows[1:] {
+		assetType := value(row, "type")
+		if assetType == "" {
+			continue
+		}
+
+		asset := models.Asset{
+			ID:         value(row, "id"),
+			Type:       assetType,
+			Name:       value(row, "name"),
+			Cloud:      value(row, "cloud"),
+			Project:    value(row, "project"),
+			SourceType: "inventory",
+		}
+		if s := value(row, "currentInstanceCount"); s != "" {
+			if asset.CurrentInstanceCount, err = strconv.Atoi(s); err != nil {
+				return nil, fmt.Errorf("inventory CSV row %d: invalid current instance count %q", i+1, s)
+			}
+		}
+		if s := value(row, "deploymentCount"); s != "" {
+			if asset.DeploymentCount, err = strconv.Atoi(s); err != nil {
+				return nil, fmt.Errorf("inventory CSV row %d: invalid deployment count %q", i+1, s)
+			}
+		}
+		assets = append(assets, asset)
+	}
+
+	return assets, nil
+}
@@ -0,0 +1,49 @@
+package inventory
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ozwilder/CloudCostCalaCLI/internal/config"
+	"github.com/ozwilder/CloudCostCalaCLI/internal/models"
+)
+
+// Formats lists the accepted inventory file formats
+var Formats = []string{"json", "csv", "tfstate"}
+
+// LoadInventory reads the current asset inventory from a JSON []models.Asset file, a CSV
+// file (columns mapped with cfg.CSVColumns) or a Terraform state file
+func LoadInventory(filePath, format string, cfg config.InventoryConfig) ([]models.Asset, error) {
+	switch strings.ToLower(format) {
+	case "", "json":
+		return loadJSON(filePath)
+	case "csv":
+		return loadCSV(filePath, cfg.CSVColumns)
+	case "tfstate":
+		return loadTerraformState(filePath)
+	default:
+		return nil, fmt.Errorf("unknown inventory format %q (expected one of: %s)", format, strings.Join(Formats, ", "))
+	}
+}
+
+// loadJSON reads a JSON array of assets
+func loadJSON(filePath string) ([]models.Asset, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read inventory file: %w", err)
+	}
+
+	var assets []models.Asset
+	if err := json.Unmarshal(data, &assets); err != nil {
+		return nil, fmt.Errorf("failed to parse inventory JSON: %w", err)
+	}
+	for i := range assets {
+		if assets[i].SourceType == "" {
+			assets[i].SourceType = "inventory"
+		}
+	}
+
+	return assets, nil
+}
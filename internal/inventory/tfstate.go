@@ -0,0 +1,101 @@
+package inventory
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ozwilder/CloudCostCalaCLI/internal/models"
+)
+
+// terraformTypes maps Terraform resource types to asset types; other resources are ignored
+var terraformTypes = map[string]string{
+	"aws_instance":                    "VM",
+	"azurerm_linux_virtual_machine":   "VM",
+	"azurerm_windows_virtual_machine": "VM",
+	"azurerm_virtual_machine":         "VM",
+	"google_compute_instance":         "VM",
+	"aws_db_instance":                 "Database",
+	"aws_rds_cluster":                 "Database",
+	"aws_dynamodb_table":              "Database",
+	"azurerm_mssql_database":          "Database",
+	"azurerm_cosmosdb_account":        "Database",
+	"google_sql_database_instance":    "Database",
+	"aws_ecs_service":                 "Container",
+	"aws_eks_cluster":                 "Container",
+	"azurerm_kubernetes_cluster":      "Container",
+	"azurerm_container_group":         "Container",
+	"google_container_cluster":        "Container",
+	"google_cloud_run_service":        "Container",
+	"aws_lambda_function":             "Function",
+	"azurerm_function_app":            "Function",
+	"azurerm_linux_function_app":      "Function",
+	"google_cloudfunctions_function":  "Function",
+	"aws_s3_bucket":                   "Storage",
+	"azurerm_storage_account":         "Storage",
+	"google_storage_bucket":           "Storage",
+}
+
+// terraformState is the subset of a Terraform state file (format version 4) used here
+type terraformState struct {
+	Resources []struct {
+		Mode      string `json:"mode"`
+		Type      string `json:"type"`
+		Name      string `json:"name"`
+		Instances []struct {
+			Attributes map[string]interface{} `json:"attributes"`
+		} `json:"instances"`
+	} `json:"resources"`
+}
+
+// loadTerraformState reads managed resources from a Terraform state file, one asset per
+// resource instance of a known type, each counted as one deployment
+func loadTerraformState(filePath string) ([]models.Asset, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Terraform state: %w", err)
+	}
+
+	var state terraformState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse Terraform state: %w", err)
+	}
+
+	assets := make([]models.Asset, 0)
+	for _, resource := range state.Resources {
+		assetType, known := terraformTypes[resource.Type]
+		if resource.Mode != "managed" || !known {
+			continue
+		}
+
+		for _, instance := range resource.Instances {
+			id, _ := instance.Attributes["id"].(string)
+			assets = append(assets, models.Asset{
+				ID:                   id,
+				Type:                 assetType,
+				Name:                 resource.Type + "." + resource.Name,
+				Cloud:                terraformCloud(resource.Type),
+				CurrentInstanceCount: 1,
+				DeploymentCount:      1,
+				SourceType:           "inventory",
+			})
+		}
+	}
+
+	return assets, nil
+}
+
+// terraformCloud returns the cloud of a Terraform resource type from its provider prefix
+func terraformCloud(resourceType string) string {
+	switch {
+	case strings.HasPrefix(resourceType, "aws_"):
+		return "AWS"
+	case strings.HasPrefix(resourceType, "azurerm_"):
+		return "Azure"
+	case strings.HasPrefix(resourceType, "google_"):
+		return "GCP"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,107 @@
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Counter is a named count for one provider, e.g. billing_records_parsed{provider="aws"}
+type Counter struct {
+	Name     string
+	Provider string
+	Value    int
+}
+
+// Push sends counters to a metrics endpoint. statsd://host:port sends StatsD counters over
+// UDP as <name>.<provider>; http(s):// URLs are treated as a Prometheus pushgateway, and
+// the counters are pushed under job "cloudcostcala" unless the URL already names a job.
+func Push(endpoint string, counters []Counter) error {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return fmt.Errorf("invalid metrics endpoint %q: %w", endpoint, err)
+	}
+
+	switch u.Scheme {
+	case "statsd":
+		return pushStatsD(u.Host, counters)
+	case "http", "https":
+		return pushGateway(u, counters)
+	default:
+		return fmt.Errorf("unsupported metrics endpoint %q: use statsd://host:port or an http(s) pushgateway URL", endpoint)
+	}
+}
+
+// pushStatsD writes one counter per line in a single UDP packet
+func pushStatsD(addr string, counters []Counter) error {
+	if addr == "" {
+		return fmt.Errorf("statsd endpoint has no host:port")
+	}
+
+	var b strings.Builder
+	for _, c := range counters {
+		fmt.Fprintf(&b, "%s.%s:%d|c\n", c.Name, c.Provider, c.Value)
+	}
+
+	conn, err := net.DialTimeout("udp", addr, 5*time.Second)
+	if err != nil {
+		return fmt.Errorf("failed to connect to statsd at %s: %w", addr, err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte(b.String())); err != nil {
+		return fmt.Errorf("failed to send statsd metrics: %w", err)
+	}
+	return nil
+}
+
+// pushGateway replaces the job's metrics on a Prometheus pushgateway
+func pushGateway(u *url.URL, counters []Counter) error {
+	if !strings.Contains(u.Path, "/metrics/job/") {
+		u = u.JoinPath("metrics", "job", "cloudcostcala")
+	}
+
+	// Group samples by metric so each gets a single TYPE line
+	byName := make(map[string][]Counter)
+	for _, c := range counters {
+		byName[c.Name] = append(byName[c.Name], c)
+	}
+	names := make([]string, 0, len(byName))
+	for name := range byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var body bytes.Buffer
+	for _, name := range names {
+		fmt.Fprintf(&body, "# TYPE %s counter\n", name)
+		for _, c := range byName[name] {
+			fmt.Fprintf(&body, "%s{provider=%q} %d\n", name, c.Provider, c.Value)
+		}
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	req, err := http.NewRequest(http.MethodPut, u.String(), &body)
+	if err != nil {
+		return fmt.Errorf("failed to create pushgateway request: %w", err)
+	}
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to push metrics: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("pushgateway returned HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
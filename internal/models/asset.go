@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 type Asset struct {
 	ID                   string                 `json:"id"`
 	Type                 string                 `json:"type"` // VM, Database, Container, Storage, Function
@@ -8,18 +10,25 @@ type Asset struct {
 	Project              string                 `json:"project"`
 	CurrentInstanceCount int                    `json:"current_instance_count"`
 	Metadata             map[string]interface{} `json:"metadata"`
-	SourceType           string                 `json:"source_type"` // inventory or billing
+	SourceType           string                 `json:"source_type"`      // inventory or billing
+	DeploymentCount      int                    `json:"deployment_count"` // Deployments from Terraform state or Kubernetes inventory
 }
 
 type BillingRecord struct {
-	ServiceName    string
-	ResourceType   string // VM, Database, Container, etc.
-	ResourceID     string
-	InstanceHours  float64
-	TimePeriod     string // YYYY-MM
-	Region         string
-	Project        string
-	Metadata       map[string]string
+	Provider      string // AWS, Azure or GCP
+	ServiceName   string
+	ResourceType  string // VM, Database, Container, etc.
+	ResourceID    string
+	SourceRow     int // Billing file row: CSV row index (header is 0) or 1-based JSON object position
+	InstanceHours float64
+	Cost          float64   // Billed cost for the record, 0 when the export has no cost column
+	StartTime     time.Time // Usage start, zero when the export has no start time column
+	IsMarketplace bool      // Third-party AWS Marketplace charge (CUR vendor code set)
+	TimePeriod    string    // YYYY-MM
+	Region        string
+	Project       string
+	AccountID     string // AWS account ID, Azure subscription or GCP project
+	Metadata      map[string]string
 }
 
 type EnrichedAsset struct {
@@ -27,13 +36,26 @@ type EnrichedAsset struct {
 	CurrentlyDeployed     int
 	AverageInstancesPerHr float64
 	HasEphemeralUsage     bool
+	EphemeralResourceIDs  []string // Billed resource IDs not present in current inventory
+	DeploymentCount       int
+	TotalCost             float64 // Sum of the type's billed record costs
+	CostPerDeployment     float64 // TotalCost / DeploymentCount; 0 when there are no deployments
+	SUDDiscount           float64 // Instance-hour weighted GCP sustained-use discount rate (0-0.30)
 	CalculatedUnits       int
 }
 
 type AggregatedOutput struct {
-	AssetType             string
-	CurrentCount          int
-	EphemeralCount        int
-	AvgInstancesPerHour   float64
-	SyntheticUnits        int
+	AssetType           string           `json:"asset_type"`
+	CurrentCount        int              `json:"current_count"`
+	EphemeralCount      int              `json:"ephemeral_count"`
+	AvgInstancesPerHour float64          `json:"avg_instances_per_hour"`
+	SyntheticUnits      int              `json:"synthetic_units"`
+	CPIScore            float64          `json:"cpi_score,omitempty"` // Budgeted / actual units; 0 when not budgeted
+	EstimatedCost       float64          `json:"estimated_cost,omitempty"`
+	Currency            string           `json:"currency,omitempty"`
+	DeploymentCount     int              `json:"deployment_count,omitempty"`
+	CostPerDeployment   float64          `json:"cost_per_deployment,omitempty"` // Billed cost per deployment; 0 when there are no deployments
+	PercentileRank      float64          `json:"percentile_rank,omitempty"`     // Position (0-100) in the industry baseline
+	BilledCost          float64          `json:"billed_cost,omitempty"`         // Sum of billed record costs; 0 when the exports have no cost
+	SourceRows          map[string][]int `json:"source_rows,omitempty"`         // Provider -> billing file rows behind this type
 }
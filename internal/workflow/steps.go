@@ -0,0 +1,81 @@
+package workflow
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// NotifySlack posts a plain-text message to a Slack incoming webhook
+func NotifySlack(webhookURL, text string) error {
+	if webhookURL == "" {
+		return fmt.Errorf("slack webhook URL is not set")
+	}
+
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return fmt.Errorf("failed to encode slack message: %w", err)
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Post(webhookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to post slack message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("slack webhook returned status %s", resp.Status)
+	}
+
+	return nil
+}
+
+// Archive writes the given files into a ZIP archive at dest
+func Archive(dest string, files []string) error {
+	out, err := os.Create(dest)
+	if err != nil {
+		return fmt.Errorf("failed to create archive: %w", err)
+	}
+	defer out.Close()
+
+	zw := zip.NewWriter(out)
+	for _, path := range files {
+		if err := addToArchive(zw, path); err != nil {
+			zw.Close()
+			return err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to finalize archive: %w", err)
+	}
+
+	return nil
+}
+
+// addToArchive copies a single file into the archive under its base name
+func addToArchive(zw *zip.Writer, path string) error {
+	in, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open %s for archiving: %w", path, err)
+	}
+	defer in.Close()
+
+	w, err := zw.Create(filepath.Base(path))
+	if err != nil {
+		return fmt.Errorf("failed to add %s to archive: %w", path, err)
+	}
+
+	if _, err := io.Copy(w, in); err != nil {
+		return fmt.Errorf("failed to write %s to archive: %w", path, err)
+	}
+
+	return nil
+}
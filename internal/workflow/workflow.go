@@ -0,0 +1,81 @@
+package workflow
+
+import (
+	"fmt"
+
+	"github.com/ozwilder/CloudCostCalaCLI/internal/config"
+)
+
+// StepFunc executes a single workflow step
+type StepFunc func(step config.WorkflowStep) error
+
+// Order returns steps sorted so every step follows its dependencies.
+// Steps without an ordering constraint keep their declared order.
+func Order(steps []config.WorkflowStep) ([]config.WorkflowStep, error) {
+	byName := make(map[string]int, len(steps))
+	for i, step := range steps {
+		if _, exists := byName[step.Name]; exists {
+			return nil, fmt.Errorf("duplicate workflow step name: %s", step.Name)
+		}
+		byName[step.Name] = i
+	}
+
+	// Count unmet dependencies and record reverse edges
+	pending := make([]int, len(steps))
+	dependents := make([][]int, len(steps))
+	for i, step := range steps {
+		for _, dep := range step.DependsOn {
+			j, exists := byName[dep]
+			if !exists {
+				return nil, fmt.Errorf("workflow step %s depends on unknown step %s", step.Name, dep)
+			}
+			pending[i]++
+			dependents[j] = append(dependents[j], i)
+		}
+	}
+
+	ordered := make([]config.WorkflowStep, 0, len(steps))
+	done := make([]bool, len(steps))
+	for len(ordered) < len(steps) {
+		progressed := false
+		for i, step := range steps {
+			if done[i] || pending[i] > 0 {
+				continue
+			}
+			done[i] = true
+			progressed = true
+			ordered = append(ordered, step)
+			for _, d := range dependents[i] {
+				pending[d]--
+			}
+		}
+		if !progressed {
+			return nil, fmt.Errorf("workflow contains a dependency cycle")
+		}
+	}
+
+	return ordered, nil
+}
+
+// Run executes steps in dependency order, stopping at the first failure
+func Run(steps []config.WorkflowStep, handlers map[string]StepFunc) error {
+	ordered, err := Order(steps)
+	if err != nil {
+		return err
+	}
+
+	// Reject unknown step types before running anything
+	for _, step := range ordered {
+		if _, exists := handlers[step.Type]; !exists {
+			return fmt.Errorf("workflow step %s has unknown type: %s", step.Name, step.Type)
+		}
+	}
+
+	for _, step := range ordered {
+		if err := handlers[step.Type](step); err != nil {
+			return fmt.Errorf("workflow step %s failed: %w", step.Name, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package cloudcost
+
+import (
+	"github.com/ozwilder/CloudCostCalaCLI/internal/assets"
+	"github.com/ozwilder/CloudCostCalaCLI/internal/billing"
+	"github.com/ozwilder/CloudCostCalaCLI/internal/config"
+)
+
+// Re-exported threshold and benchmark types
+type (
+	ThresholdViolation = billing.ThresholdViolation
+	ShareViolation     = billing.ShareViolation
+	IndustryBaseline   = config.IndustryBaseline
+)
+
+// ByProvider returns the last Run's rows for each provider, built from that provider's
+// records and the inventory assets whose Cloud matches it
+func (p *Pipeline) ByProvider() map[string][]AggregatedOutput {
+	byProvider := make(map[string][]AggregatedOutput, len(p.recordsByProvider))
+	for provider, records := range p.recordsByProvider {
+		byProvider[provider] = p.aggregate(assets.FilterByCloud(p.inventory, provider), records,
+			p.cfg.Billing.Periods(), p.billingPeriod)
+	}
+	return byProvider
+}
+
+// ByDimension returns the last Run's rows per provider, region, project or tag:<key> group.
+// Provider groups hold the provider's inventory; other groups hold the assets they bill.
+func (p *Pipeline) ByDimension(dimension string) (map[string][]AggregatedOutput, error) {
+	groups, err := billing.GroupByDimension(p.recordsByProvider, dimension)
+	if err != nil {
+		return nil, err
+	}
+
+	inventoryByGroup := assets.SplitInventory(p.inventory, groups, p.cfg.FieldMasking)
+	byGroup := make(map[string][]AggregatedOutput, len(groups))
+	for group, records := range groups {
+		inventory := inventoryByGroup[group]
+		if dimension == "provider" {
+			inventory = assets.FilterByCloud(p.inventory, group)
+		}
+		byGroup[group] = p.aggregate(inventory, records, p.cfg.Billing.Periods(), p.billingPeriod)
+	}
+	return byGroup, nil
+}
+
+// ByVPCGroup returns the last Run's rows per configured VPC group, or nil when none are configured
+func (p *Pipeline) ByVPCGroup() map[string][]AggregatedOutput {
+	if len(p.cfg.VPCGroups.Groups) == 0 {
+		return nil
+	}
+
+	groups := billing.GroupByVPC(p.records, p.cfg.VPCGroups)
+	inventoryByGroup := assets.SplitInventory(p.inventory, groups, p.cfg.FieldMasking)
+	byGroup := make(map[string][]AggregatedOutput, len(groups))
+	for group, records := range groups {
+		byGroup[group] = p.aggregate(inventoryByGroup[group], records, p.cfg.Billing.Periods(), p.billingPeriod)
+	}
+	return byGroup
+}
+
+// ByPeriod returns the last Run's rows per billing period (YYYY-MM), each normalized over
+// its own period and holding the inventory assets billed in it
+func (p *Pipeline) ByPeriod() map[string][]AggregatedOutput {
+	recordsByPeriod := billing.SplitByPeriod(p.records)
+	inventoryByPeriod := assets.SplitInventory(p.inventory, recordsByPeriod, p.cfg.FieldMasking)
+	byPeriod := make(map[string][]AggregatedOutput, len(recordsByPeriod))
+	for period, records := range recordsByPeriod {
+		byPeriod[period] = p.aggregate(inventoryByPeriod[period], records, nil, period)
+	}
+	return byPeriod
+}
+
+// CheckThresholds compares rows against the configured synthetic-unit limits
+func (p *Pipeline) CheckThresholds(rows []AggregatedOutput) []ThresholdViolation {
+	return billing.CheckThresholds(rows, p.cfg.Thresholds)
+}
+
+// CheckShareThreshold returns the asset types whose share of total synthetic units is
+// greater than maxShare (a fraction such as 0.60)
+func CheckShareThreshold(rows []AggregatedOutput, maxShare float64) []ShareViolation {
+	return billing.CheckShareThreshold(rows, maxShare)
+}
+
+// LoadIndustryBaselines reads a JSON array of industry baselines
+func LoadIndustryBaselines(filePath string) ([]IndustryBaseline, error) {
+	return config.LoadIndustryBaselines(filePath)
+}
+
+// ApplyBenchmark sets each row's percentile rank within its type's industry baseline
+func ApplyBenchmark(rows []AggregatedOutput, baselines []IndustryBaseline) []AggregatedOutput {
+	return assets.ApplyBenchmark(rows, baselines)
+}
+
+// aggregate builds the output rows for a subset of the last Run's records the way Run does
+// for all of them. Each provider's records are normalized over its period in periods,
+// falling back to period.
+func (p *Pipeline) aggregate(inventory []Asset, records []BillingRecord, periods map[string]string,
+	period string) []AggregatedOutput {
+	recordsByProvider := billing.SplitByProvider(records)
+	avgInstancesByType := billing.AggregateByProvider(recordsByProvider, periods, period, p.cfg.SyntheticUnits)
+	return p.rows(inventory, records, recordsByProvider, avgInstancesByType)
+}
@@ -0,0 +1,40 @@
+package cloudcost
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ozwilder/CloudCostCalaCLI/internal/billing"
+)
+
+// VMHoursDiscrepancy is a VM whose billed instance-hours and Azure Monitor hours disagree
+type VMHoursDiscrepancy = billing.VMHoursDiscrepancy
+
+// AzureCredentials identify the service principal that reads Azure Monitor metrics
+type AzureCredentials struct {
+	SubscriptionID string
+	TenantID       string
+	ClientID       string
+	ClientSecret   string
+}
+
+// CrossCheckAzureMonitor fetches Azure Monitor CPU hours over the Azure billing period
+// (billing.azure.period, or the period detected by the last Run) and compares them with
+// the last Run's billed Azure VM instance-hours. Requests are limited to
+// billing.azure.apiRequestsPerSecond.
+func (p *Pipeline) CrossCheckAzureMonitor(ctx context.Context, creds AzureCredentials) ([]VMHoursDiscrepancy, error) {
+	period := p.cfg.Billing.Azure.Period
+	if period == "" {
+		period = p.billingPeriod
+	}
+
+	monitored, err := billing.FetchAzureMonitorCPUHours(ctx, creds.SubscriptionID, creds.TenantID,
+		creds.ClientID, creds.ClientSecret, period, p.limiters["Azure"])
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch Azure Monitor CPU hours: %w", err)
+	}
+	// Match the billed records, which are masked while parsing
+	billing.MaskRecords(monitored, p.cfg.FieldMasking)
+
+	return billing.CrossCheckAzureVMHours(p.recordsByProvider["Azure"], monitored), nil
+}
@@ -0,0 +1,17 @@
+// Package cloudcost exposes the CloudCostCalaCLI processing pipeline as a library.
+//
+// A Pipeline parses the billing files named in a Config, normalizes them to
+// average instances per hour, enriches them with the current inventory and
+// returns one aggregated row per asset type:
+//
+//	cfg, err := cloudcost.LoadConfig("config.json")
+//	if err != nil {
+//		return err
+//	}
+//	rows, err := cloudcost.NewPipeline(cfg).Run(ctx)
+//
+// Parsing, enrichment and output encoding can each be replaced with options
+// such as WithBillingParser, which is useful for testing with in-memory data.
+// After Run, ByProvider, ByPeriod, ByVPCGroup and ByDimension return the rows
+// split the way the CLI's per-provider, per-period and group sheets are.
+package cloudcost
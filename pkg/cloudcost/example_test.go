@@ -0,0 +1,41 @@
+package cloudcost_test
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ozwilder/CloudCostCalaCLI/pkg/cloudcost"
+)
+
+// memoryParser serves billing records from memory instead of reading files
+type memoryParser map[string][]cloudcost.BillingRecord
+
+func (m memoryParser) Parse(filePath, cloudProvider string) ([]cloudcost.BillingRecord, error) {
+	return m[cloudProvider], nil
+}
+
+func Example() {
+	cfg := &cloudcost.Config{}
+	cfg.Billing.AWS.FilePath = "aws-billing.csv"
+	cfg.SyntheticUnits.Rules = map[string]cloudcost.SyntheticUnitRule{
+		"VM": {UnitsPerInstance: 5},
+	}
+
+	parser := memoryParser{
+		"aws": {
+			{ResourceType: "VM", ResourceID: "i-1", InstanceHours: 744, TimePeriod: "2024-01"},
+			{ResourceType: "VM", ResourceID: "i-2", InstanceHours: 372, TimePeriod: "2024-01"},
+		},
+	}
+
+	rows, err := cloudcost.NewPipeline(cfg, cloudcost.WithBillingParser(parser)).Run(context.Background())
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	for _, row := range rows {
+		fmt.Printf("%s: %.2f instances/hr, %d synthetic units\n", row.AssetType, row.AvgInstancesPerHour, row.SyntheticUnits)
+	}
+	// Output:
+	// VM: 1.50 instances/hr, 8 synthetic units
+}
@@ -0,0 +1,333 @@
+package cloudcost
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ozwilder/CloudCostCalaCLI/internal/assets"
+	"github.com/ozwilder/CloudCostCalaCLI/internal/billing"
+	"github.com/ozwilder/CloudCostCalaCLI/internal/config"
+	"github.com/ozwilder/CloudCostCalaCLI/internal/models"
+	"github.com/ozwilder/CloudCostCalaCLI/pkg/output"
+)
+
+// Re-exported types so library users can name them outside this module
+type (
+	Config               = config.Config
+	SyntheticUnitsConfig = config.SyntheticUnitsConfig
+	SyntheticUnitRule    = config.SyntheticUnitRule
+	FieldMaskingConfig   = config.FieldMaskingConfig
+	Asset                = models.Asset
+	BillingRecord        = models.BillingRecord
+	EnrichedAsset        = models.EnrichedAsset
+	AggregatedOutput     = models.AggregatedOutput
+	Parser               = billing.Parser
+	FileParser           = billing.FileParser
+	ParseOptions         = billing.ParseOptions
+	AuditLog             = billing.AuditLog
+	AuditEntry           = billing.AuditEntry
+	Transform            = billing.Transform
+)
+
+// LoadConfig reads and parses a configuration file
+func LoadConfig(filePath string) (*Config, error) {
+	return config.LoadConfig(filePath)
+}
+
+// LoadTransform reads a Starlark transform script for ParseOptions.Transform
+func LoadTransform(filePath string) (*Transform, error) {
+	return billing.LoadTransform(filePath)
+}
+
+// Enricher merges inventory with normalized billing data
+type Enricher func(inventory []Asset, records []BillingRecord,
+	avgInstancesByType map[string]float64, rules SyntheticUnitsConfig) []EnrichedAsset
+
+// OutputWriter encodes aggregated rows to a writer
+type OutputWriter func(w io.Writer, aggregated []AggregatedOutput) error
+
+// Hooks are optional callbacks invoked while a pipeline runs
+type Hooks struct {
+	BeforeParse func(provider string)
+	AfterParse  func(provider string, records []BillingRecord, err error)
+}
+
+// Option customizes a Pipeline
+type Option func(*Pipeline)
+
+// WithBillingParser replaces the billing file parser
+func WithBillingParser(p Parser) Option {
+	return func(pl *Pipeline) { pl.parser = p }
+}
+
+// WithEnricher replaces the inventory/billing enrichment step
+func WithEnricher(e Enricher) Option {
+	return func(pl *Pipeline) { pl.enrich = e }
+}
+
+// WithOutputWriter replaces the encoder used by RunWithWriter (JSON by default)
+func WithOutputWriter(w OutputWriter) Option {
+	return func(pl *Pipeline) { pl.writer = w }
+}
+
+// WithInventory sets the current asset inventory to enrich
+func WithInventory(inventory []Asset) Option {
+	return func(pl *Pipeline) { pl.inventory = inventory }
+}
+
+// WithInstanceHoursPrecision rounds parsed instance-hours to the given decimal places
+func WithInstanceHoursPrecision(precision int) Option {
+	return func(pl *Pipeline) { pl.precision = precision }
+}
+
+// WithTypeFilter restricts processing to the given asset types (case-insensitive)
+func WithTypeFilter(types []string) Option {
+	return func(pl *Pipeline) { pl.types = types }
+}
+
+// WithEphemeralOnly keeps only asset types billed but absent from the inventory
+func WithEphemeralOnly(ephemeralOnly bool) Option {
+	return func(pl *Pipeline) { pl.ephemeralOnly = ephemeralOnly }
+}
+
+// WithBillingPeriodAuto infers the billing period from the most common record period
+// instead of the first record's
+func WithBillingPeriodAuto(auto bool) Option {
+	return func(pl *Pipeline) { pl.periodAuto = auto }
+}
+
+// WithHooks sets callbacks for progress reporting
+func WithHooks(h Hooks) Option {
+	return func(pl *Pipeline) { pl.hooks = h }
+}
+
+// Pipeline runs billing parsing, normalization, enrichment and aggregation
+type Pipeline struct {
+	cfg           *config.Config
+	parser        billing.Parser
+	enrich        Enricher
+	writer        OutputWriter
+	inventory     []models.Asset
+	precision     int
+	types         []string
+	ephemeralOnly bool
+	periodAuto    bool
+	hooks         Hooks
+	limiters      map[string]*billing.RateLimiter // Per provider name, from billing.*.apiRequestsPerSecond
+
+	// Populated by Run
+	records            []models.BillingRecord
+	recordsByProvider  map[string][]models.BillingRecord
+	billingPeriod      string
+	avgInstancesByType map[string]float64
+}
+
+// NewPipeline creates a pipeline for the given config
+func NewPipeline(cfg *Config, opts ...Option) *Pipeline {
+	p := &Pipeline{
+		cfg: cfg,
+		parser: billing.FileParser{Options: billing.ParseOptions{
+			GCPFormat:     cfg.Billing.GCP.Format,
+			SkipRows:      cfg.Billing.SkipRows(),
+			AzureTimezone: cfg.Billing.Azure.Timezone,
+			Masking:       cfg.FieldMasking,
+		}},
+		enrich:    assets.EnrichAssets,
+		writer:    output.EncodeJSON,
+		inventory: make([]models.Asset, 0),
+		precision: -1,
+	}
+	p.limiters = make(map[string]*billing.RateLimiter)
+	for provider, requestsPerSecond := range cfg.Billing.RequestsPerSecond() {
+		p.limiters[provider] = billing.NewRateLimiter(requestsPerSecond)
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Run processes every configured billing file and returns one row per asset type.
+// Providers whose files fail to parse are skipped; Run fails only when no records load.
+func (p *Pipeline) Run(ctx context.Context) ([]AggregatedOutput, error) {
+	providers := []struct {
+		key, name, filePath string
+		kafka               config.KafkaConfig
+	}{
+		{"aws", "AWS", p.cfg.Billing.AWS.FilePath, p.cfg.Billing.AWS.Kafka},
+		{"azure", "Azure", p.cfg.Billing.Azure.FilePath, p.cfg.Billing.Azure.Kafka},
+		{"gcp", "GCP", p.cfg.Billing.GCP.FilePath, p.cfg.Billing.GCP.Kafka},
+	}
+
+	p.records = make([]models.BillingRecord, 0)
+	p.recordsByProvider = make(map[string][]models.BillingRecord)
+
+	for _, provider := range providers {
+		if provider.filePath == "" && provider.kafka.Topic == "" {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		if p.hooks.BeforeParse != nil {
+			p.hooks.BeforeParse(provider.name)
+		}
+		var records []models.BillingRecord
+		var err error
+		if provider.filePath == "" {
+			records, err = p.consumeKafka(ctx, provider.kafka, provider.key)
+		} else {
+			records, err = p.parser.Parse(provider.filePath, provider.key)
+		}
+		if err == nil && provider.filePath != "" {
+			if _, ok := p.parser.(billing.FileParser); !ok {
+				// FileParser masks while reading; other parsers' records are masked here
+				billing.MaskRecords(records, p.cfg.FieldMasking)
+			}
+		}
+		if p.hooks.AfterParse != nil {
+			p.hooks.AfterParse(provider.name, records, err)
+		}
+		if err != nil {
+			continue
+		}
+
+		// Strip floating-point noise before any aggregation
+		billing.RoundInstanceHours(records, p.precision)
+
+		p.records = append(p.records, records...)
+		p.recordsByProvider[provider.name] = records
+	}
+
+	if len(p.records) == 0 {
+		return nil, fmt.Errorf("no billing records loaded; check the billing file paths and Kafka topics in the config")
+	}
+
+	if p.periodAuto {
+		p.billingPeriod = billing.InferBillingPeriod(p.records)
+	} else {
+		p.billingPeriod = billing.GetBillingPeriod(p.records)
+	}
+	p.avgInstancesByType = billing.AggregateByProvider(p.recordsByProvider, p.cfg.Billing.Periods(),
+		p.billingPeriod, p.cfg.SyntheticUnits)
+
+	inventory := p.inventory
+	if len(p.types) > 0 {
+		p.filterTypes()
+		inventory = filterInventory(inventory, p.types)
+	}
+
+	return p.rows(inventory, p.records, p.recordsByProvider, p.avgInstancesByType), nil
+}
+
+// consumeKafka reads the backlog of a provider's billing topic, using the FileParser's
+// options when the pipeline has one so masking and transforms match file parsing
+func (p *Pipeline) consumeKafka(ctx context.Context, kafkaCfg config.KafkaConfig,
+	provider string) ([]models.BillingRecord, error) {
+	opts := billing.ParseOptions{Masking: p.cfg.FieldMasking}
+	if parser, ok := p.parser.(billing.FileParser); ok {
+		opts = parser.Options
+	}
+
+	records := make([]models.BillingRecord, 0)
+	err := billing.StreamKafkaBilling(ctx, kafkaCfg, provider, opts, billing.DefaultBatchSize,
+		func(batch []models.BillingRecord) error {
+			records = append(records, batch...)
+			return nil
+		})
+	return records, err
+}
+
+// rows enriches inventory with normalized billing data and returns the filtered, priced rows
+func (p *Pipeline) rows(inventory []models.Asset, records []models.BillingRecord,
+	recordsByProvider map[string][]models.BillingRecord, avgInstancesByType map[string]float64) []AggregatedOutput {
+	enriched := p.enrich(inventory, records, avgInstancesByType, p.cfg.SyntheticUnits)
+	if p.ephemeralOnly {
+		enriched = assets.FilterEphemeral(enriched)
+	}
+	aggregated := assets.AggregateForOutput(enriched)
+	aggregated = assets.FilterAggregated(aggregated, p.types)
+
+	lineage := billing.SourceRowsByType(recordsByProvider)
+	for i := range aggregated {
+		aggregated[i].SourceRows = lineage[aggregated[i].AssetType]
+	}
+	aggregated = assets.ApplyPricing(aggregated, p.cfg.Pricing)
+
+	if len(p.cfg.BudgetedUnits) > 0 {
+		aggregated = assets.ApplyBudgets(aggregated, p.cfg.BudgetedUnits)
+	}
+
+	return aggregated
+}
+
+// filterTypes drops records and normalized averages outside the type filter
+func (p *Pipeline) filterTypes() {
+	p.records = billing.FilterByTypes(p.records, p.types)
+
+	wanted := billing.TypeSet(p.types)
+	for provider, records := range p.recordsByProvider {
+		kept := make([]models.BillingRecord, 0, len(records))
+		for _, record := range records {
+			if wanted[strings.ToLower(record.ResourceType)] {
+				kept = append(kept, record)
+			}
+		}
+		p.recordsByProvider[provider] = kept
+	}
+
+	for assetType := range p.avgInstancesByType {
+		if !wanted[strings.ToLower(assetType)] {
+			delete(p.avgInstancesByType, assetType)
+		}
+	}
+}
+
+// filterInventory keeps inventory assets whose type is in types (case-insensitive)
+func filterInventory(inventory []models.Asset, types []string) []models.Asset {
+	wanted := billing.TypeSet(types)
+	filtered := make([]models.Asset, 0, len(inventory))
+	for _, asset := range inventory {
+		if wanted[strings.ToLower(asset.Type)] {
+			filtered = append(filtered, asset)
+		}
+	}
+	return filtered
+}
+
+// RunWithWriter runs the pipeline and encodes the result to w
+func (p *Pipeline) RunWithWriter(ctx context.Context, w io.Writer) error {
+	aggregated, err := p.Run(ctx)
+	if err != nil {
+		return err
+	}
+	return p.writer(w, aggregated)
+}
+
+// Records returns all billing records loaded by the last Run
+func (p *Pipeline) Records() []BillingRecord {
+	return p.records
+}
+
+// RecordsByProvider returns the last Run's billing records keyed by provider (AWS, Azure, GCP)
+func (p *Pipeline) RecordsByProvider() map[string][]BillingRecord {
+	return p.recordsByProvider
+}
+
+// BillingPeriod returns the billing period detected by the last Run
+func (p *Pipeline) BillingPeriod() string {
+	return p.billingPeriod
+}
+
+// AvgInstancesByType returns the normalized average instances per hour from the last Run
+func (p *Pipeline) AvgInstancesByType() map[string]float64 {
+	return p.avgInstancesByType
+}
+
+// Inventory returns the asset inventory the pipeline enriches
+func (p *Pipeline) Inventory() []Asset {
+	return p.inventory
+}
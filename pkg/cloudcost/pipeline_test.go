@@ -0,0 +1,172 @@
+package cloudcost
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ozwilder/CloudCostCalaCLI/internal/config"
+	"github.com/ozwilder/CloudCostCalaCLI/internal/models"
+)
+
+// stubParser returns fixed records for every provider file
+type stubParser struct {
+	records []models.BillingRecord
+}
+
+func (s stubParser) Parse(filePath, cloudProvider string) ([]models.BillingRecord, error) {
+	records := make([]models.BillingRecord, len(s.records))
+	copy(records, s.records)
+	return records, nil
+}
+
+func TestRunRoundsRecordsBeforeAggregating(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Billing.AWS.FilePath = "aws.csv"
+	cfg.SyntheticUnits.Rules = map[string]config.SyntheticUnitRule{"VM": {UnitsPerInstance: 5}}
+
+	p := NewPipeline(cfg,
+		WithBillingParser(stubParser{records: []models.BillingRecord{
+			{ResourceType: "VM", InstanceHours: 0.4, TimePeriod: "2024-01"},
+		}}),
+		WithInstanceHoursPrecision(0),
+	)
+	if _, err := p.Run(context.Background()); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	if got := p.Records()[0].InstanceHours; got != 0 {
+		t.Errorf("Records() instance-hours = %v, want 0", got)
+	}
+	if got := p.RecordsByProvider()["AWS"][0].InstanceHours; got != 0 {
+		t.Errorf("RecordsByProvider() instance-hours = %v, want 0", got)
+	}
+	if got := p.AvgInstancesByType()["VM"]; got != 0 {
+		t.Errorf("VM average = %v, want 0 after rounding", got)
+	}
+}
+
+// providerParser returns fixed records per provider key (aws, azure, gcp)
+type providerParser map[string][]models.BillingRecord
+
+func (p providerParser) Parse(filePath, cloudProvider string) ([]models.BillingRecord, error) {
+	return append([]models.BillingRecord(nil), p[cloudProvider]...), nil
+}
+
+// breakdownPipeline runs a pipeline over AWS and GCP VM records, with a weekly GCP period
+// and an inventory holding AWS assets only
+func breakdownPipeline(t *testing.T, opts ...Option) *Pipeline {
+	t.Helper()
+	cfg := &config.Config{}
+	cfg.Billing.AWS.FilePath = "aws.csv"
+	cfg.Billing.GCP.FilePath = "gcp.csv"
+	cfg.Billing.GCP.Period = "2024-01-01/2024-01-07"
+	cfg.SyntheticUnits.Rules = map[string]config.SyntheticUnitRule{"VM": {UnitsPerInstance: 1}}
+
+	parser := providerParser{
+		"aws": {
+			{Provider: "AWS", ResourceType: "VM", ResourceID: "i-1", InstanceHours: 744, TimePeriod: "2024-01", Region: "us-east-1"},
+			{Provider: "AWS", ResourceType: "Database", ResourceID: "db-1", InstanceHours: 744, TimePeriod: "2024-01", Region: "us-east-1"},
+		},
+		"gcp": {
+			{Provider: "GCP", ResourceType: "VM", ResourceID: "gce-1", InstanceHours: 168, TimePeriod: "2024-01", Region: "us-central1"},
+		},
+	}
+	inventory := []models.Asset{
+		{ID: "i-1", Type: "VM", Cloud: "AWS"},
+		{ID: "i-2", Type: "VM", Cloud: "AWS"},
+	}
+
+	p := NewPipeline(cfg, append([]Option{WithBillingParser(parser), WithInventory(inventory)}, opts...)...)
+	if _, err := p.Run(context.Background()); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	return p
+}
+
+// row returns the row for assetType, or a zero row when it is missing
+func row(rows []AggregatedOutput, assetType string) AggregatedOutput {
+	for _, r := range rows {
+		if r.AssetType == assetType {
+			return r
+		}
+	}
+	return AggregatedOutput{}
+}
+
+func TestByProviderSplitsInventoryAndPeriods(t *testing.T) {
+	byProvider := breakdownPipeline(t).ByProvider()
+
+	if got := row(byProvider["AWS"], "VM").CurrentCount; got != 2 {
+		t.Errorf("AWS VM current count = %d, want 2", got)
+	}
+	gcp := row(byProvider["GCP"], "VM")
+	if gcp.CurrentCount != 0 {
+		t.Errorf("GCP VM current count = %d, want 0 without GCP inventory", gcp.CurrentCount)
+	}
+	if gcp.AvgInstancesPerHour != 1 {
+		t.Errorf("GCP VM average = %v, want 1 over the weekly GCP period", gcp.AvgInstancesPerHour)
+	}
+}
+
+func TestByDimensionUsesProviderPeriods(t *testing.T) {
+	p := breakdownPipeline(t)
+
+	byRegion, err := p.ByDimension("region")
+	if err != nil {
+		t.Fatalf("ByDimension: %v", err)
+	}
+	if got := row(byRegion["us-central1"], "VM").AvgInstancesPerHour; got != 1 {
+		t.Errorf("us-central1 VM average = %v, want 1 over the weekly GCP period", got)
+	}
+	// Only i-1 is billed, so only it belongs to the us-east-1 group
+	if got := row(byRegion["us-east-1"], "VM").CurrentCount; got != 1 {
+		t.Errorf("us-east-1 VM current count = %d, want 1", got)
+	}
+
+	byProvider, err := p.ByDimension("provider")
+	if err != nil {
+		t.Fatalf("ByDimension: %v", err)
+	}
+	if got := row(byProvider["AWS"], "VM").CurrentCount; got != 2 {
+		t.Errorf("AWS group VM current count = %d, want the provider's 2 inventory assets", got)
+	}
+
+	if _, err := p.ByDimension("colour"); err == nil {
+		t.Error("ByDimension(colour) succeeded, want an unknown dimension error")
+	}
+}
+
+func TestBreakdownsApplyFilters(t *testing.T) {
+	p := breakdownPipeline(t, WithEphemeralOnly(true), WithTypeFilter([]string{"VM"}))
+
+	for name, rows := range map[string][]AggregatedOutput{
+		"AWS":     p.ByProvider()["AWS"],
+		"GCP":     p.ByProvider()["GCP"],
+		"2024-01": p.ByPeriod()["2024-01"],
+	} {
+		for _, r := range rows {
+			if r.AssetType != "VM" {
+				t.Errorf("%s holds %s, want only the filtered VM type", name, r.AssetType)
+			}
+			if r.CurrentCount > 0 {
+				t.Errorf("%s holds inventoried %s, want ephemeral types only", name, r.AssetType)
+			}
+		}
+	}
+	if len(p.ByProvider()["GCP"]) != 1 {
+		t.Errorf("GCP rows = %v, want its ephemeral VM", p.ByProvider()["GCP"])
+	}
+}
+
+func TestNewPipelineRateLimitsConfiguredProviders(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Billing.Azure.APIRequestsPerSecond = 5
+
+	p := NewPipeline(cfg)
+	if p.limiters["Azure"] == nil {
+		t.Error("no Azure limiter, want one from billing.azure.apiRequestsPerSecond")
+	}
+	if p.limiters["AWS"] != nil || p.limiters["GCP"] != nil {
+		t.Errorf("limiters = %v, want providers without apiRequestsPerSecond unlimited", p.limiters)
+	}
+}
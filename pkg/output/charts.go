@@ -0,0 +1,69 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ozwilder/CloudCostCalaCLI/internal/models"
+	"github.com/xuri/excelize/v2"
+)
+
+// AddChartsSheet adds a "Charts" sheet visualizing the asset rows written to sheet
+func AddChartsSheet(f *excelize.File, sheet string, assets []models.AggregatedOutput) error {
+	if len(assets) == 0 {
+		return nil
+	}
+
+	if _, err := f.NewSheet("Charts"); err != nil {
+		return fmt.Errorf("failed to create Charts sheet: %w", err)
+	}
+
+	// Data rows start at row 2 of the data sheet
+	lastRow := len(assets) + 1
+	ref := sheetRef(sheet)
+
+	// Bar chart of synthetic units per asset type
+	if err := f.AddChart("Charts", "A1", &excelize.Chart{
+		Type: excelize.Col,
+		Series: []excelize.ChartSeries{
+			{
+				Name:       ref + "!$E$1",
+				Categories: fmt.Sprintf("%s!$A$2:$A$%d", ref, lastRow),
+				Values:     fmt.Sprintf("%s!$E$2:$E$%d", ref, lastRow),
+			},
+		},
+		Title:     []excelize.RichTextRun{{Text: "Synthetic Units by Asset Type"}},
+		Legend:    excelize.ChartLegend{Position: "none"},
+		Dimension: excelize.ChartDimension{Width: 640, Height: 320},
+	}); err != nil {
+		return fmt.Errorf("failed to add synthetic units chart: %w", err)
+	}
+
+	// One pie chart per asset type showing current vs ephemeral count
+	for i, asset := range assets {
+		row := i + 2
+		cell := fmt.Sprintf("A%d", 18+i*16)
+		if err := f.AddChart("Charts", cell, &excelize.Chart{
+			Type: excelize.Pie,
+			Series: []excelize.ChartSeries{
+				{
+					Name:       fmt.Sprintf("%s!$A$%d", ref, row),
+					Categories: ref + "!$B$1:$C$1",
+					Values:     fmt.Sprintf("%s!$B$%d:$C$%d", ref, row, row),
+				},
+			},
+			Title:     []excelize.RichTextRun{{Text: fmt.Sprintf("%s: Current vs Ephemeral", asset.AssetType)}},
+			PlotArea:  excelize.ChartPlotArea{ShowPercent: true},
+			Dimension: excelize.ChartDimension{Width: 480, Height: 300},
+		}); err != nil {
+			return fmt.Errorf("failed to add %s pie chart: %w", asset.AssetType, err)
+		}
+	}
+
+	return nil
+}
+
+// sheetRef quotes a sheet name for use in a cell reference
+func sheetRef(sheet string) string {
+	return "'" + strings.ReplaceAll(sheet, "'", "''") + "'"
+}
@@ -0,0 +1,280 @@
+package output
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/ozwilder/CloudCostCalaCLI/internal/models"
+	"github.com/xuri/excelize/v2"
+)
+
+// ConversionColumn is an extra column expressing synthetic units in another unit system
+type ConversionColumn struct {
+	Name       string
+	Multiplier float64
+}
+
+// AddConversionColumns returns a SheetWriter that appends one column per unit system
+// to every asset sheet, computed from its Synthetic Units column
+func AddConversionColumns(columns []ConversionColumn) SheetWriter {
+	return func(f *excelize.File) error {
+		for _, sheet := range assetSheets(f) {
+			rows, err := f.GetRows(sheet)
+			if err != nil {
+				return fmt.Errorf("failed to read %s sheet: %w", sheet, err)
+			}
+			first := len(rows[0]) + 1
+
+			for i, column := range columns {
+				col, _ := excelize.ColumnNumberToName(first + i)
+				f.SetCellValue(sheet, col+"1", column.Name)
+				for row := 2; row <= len(rows); row++ {
+					f.SetCellFormula(sheet, fmt.Sprintf("%s%d", col, row), fmt.Sprintf("E%d*%g", row, column.Multiplier))
+				}
+				f.SetColWidth(sheet, col, col, 15)
+			}
+
+			// Match the header and totals row styling
+			last := first + len(columns) - 1
+			copyRowStyle(f, sheet, 1, first, last)
+			if rows[len(rows)-1][0] == "TOTAL" {
+				copyRowStyle(f, sheet, len(rows), first, last)
+			}
+		}
+		return nil
+	}
+}
+
+// AddCPIColumn returns a SheetWriter that appends a colour-coded CPI column to the
+// combined asset sheet (Sheet1 or Summary): green when CPI >= 1.0, red below 0.8
+func AddCPIColumn(aggregated []models.AggregatedOutput) SheetWriter {
+	cpiByType := make(map[string]float64, len(aggregated))
+	for _, a := range aggregated {
+		cpiByType[a.AssetType] = a.CPIScore
+	}
+
+	return func(f *excelize.File) error {
+		green, _ := f.NewConditionalStyle(&excelize.Style{
+			Font: &excelize.Font{Color: "006100"},
+			Fill: excelize.Fill{Type: "pattern", Color: []string{"C6EFCE"}, Pattern: 1},
+		})
+		red, _ := f.NewConditionalStyle(&excelize.Style{
+			Font: &excelize.Font{Color: "9C0006"},
+			Fill: excelize.Fill{Type: "pattern", Color: []string{"FFC7CE"}, Pattern: 1},
+		})
+
+		for _, sheet := range assetSheets(f) {
+			if sheet != "Sheet1" && sheet != "Summary" {
+				continue
+			}
+			rows, err := f.GetRows(sheet)
+			if err != nil {
+				return fmt.Errorf("failed to read %s sheet: %w", sheet, err)
+			}
+			col, _ := excelize.ColumnNumberToName(len(rows[0]) + 1)
+
+			f.SetCellValue(sheet, col+"1", "CPI")
+			for row := 2; row <= len(rows); row++ {
+				assetType := rows[row-1][0]
+				if assetType == "TOTAL" {
+					continue
+				}
+				cell := fmt.Sprintf("%s%d", col, row)
+				if cpi := cpiByType[assetType]; cpi > 0 {
+					f.SetCellValue(sheet, cell, math.Round(cpi*100)/100)
+				} else {
+					f.SetCellValue(sheet, cell, "N/A")
+				}
+			}
+			f.SetColWidth(sheet, col, col, 10)
+			copyRowStyle(f, sheet, 1, len(rows[0])+1, len(rows[0])+1)
+
+			// Formula rules keep the N/A text cells uncoloured
+			first := fmt.Sprintf("%s2", col)
+			if err := f.SetConditionalFormat(sheet, fmt.Sprintf("%s:%s%d", first, col, len(rows)), []excelize.ConditionalFormatOptions{
+				{Type: "formula", Criteria: fmt.Sprintf("AND(ISNUMBER(%s),%s>=1)", first, first), Format: &green},
+				{Type: "formula", Criteria: fmt.Sprintf("AND(ISNUMBER(%s),%s<0.8)", first, first), Format: &red},
+			}); err != nil {
+				return fmt.Errorf("failed to format CPI column: %w", err)
+			}
+		}
+		return nil
+	}
+}
+
+// AddBenchmarkColumn returns a SheetWriter that appends a "Benchmark" column with each
+// type's industry percentile rank to the combined asset sheet (Sheet1 or Summary)
+func AddBenchmarkColumn(aggregated []models.AggregatedOutput, benchmarked map[string]bool) SheetWriter {
+	rankByType := make(map[string]float64, len(aggregated))
+	for _, a := range aggregated {
+		rankByType[a.AssetType] = a.PercentileRank
+	}
+
+	return func(f *excelize.File) error {
+		for _, sheet := range assetSheets(f) {
+			if sheet != "Sheet1" && sheet != "Summary" {
+				continue
+			}
+			rows, err := f.GetRows(sheet)
+			if err != nil {
+				return fmt.Errorf("failed to read %s sheet: %w", sheet, err)
+			}
+			col, _ := excelize.ColumnNumberToName(len(rows[0]) + 1)
+
+			f.SetCellValue(sheet, col+"1", "Benchmark")
+			for row := 2; row <= len(rows); row++ {
+				assetType := rows[row-1][0]
+				if assetType == "TOTAL" {
+					continue
+				}
+				cell := fmt.Sprintf("%s%d", col, row)
+				if benchmarked[assetType] {
+					f.SetCellValue(sheet, cell, fmt.Sprintf("P%.0f", rankByType[assetType]))
+				} else {
+					f.SetCellValue(sheet, cell, "N/A")
+				}
+			}
+			f.SetColWidth(sheet, col, col, 12)
+			copyRowStyle(f, sheet, 1, len(rows[0])+1, len(rows[0])+1)
+		}
+		return nil
+	}
+}
+
+// AddYoYColumn returns a SheetWriter that appends a "YoY Growth" column to the combined
+// asset sheet (Sheet1 or Summary): red when usage grew year over year, green when it shrank
+func AddYoYColumn(growth map[string]float64) SheetWriter {
+	return func(f *excelize.File) error {
+		red, _ := f.NewConditionalStyle(&excelize.Style{
+			Font: &excelize.Font{Color: "9C0006"},
+			Fill: excelize.Fill{Type: "pattern", Color: []string{"FFC7CE"}, Pattern: 1},
+		})
+		green, _ := f.NewConditionalStyle(&excelize.Style{
+			Font: &excelize.Font{Color: "006100"},
+			Fill: excelize.Fill{Type: "pattern", Color: []string{"C6EFCE"}, Pattern: 1},
+		})
+		percent, _ := f.NewStyle(&excelize.Style{NumFmt: 10})
+
+		for _, sheet := range assetSheets(f) {
+			if sheet != "Sheet1" && sheet != "Summary" {
+				continue
+			}
+			rows, err := f.GetRows(sheet)
+			if err != nil {
+				return fmt.Errorf("failed to read %s sheet: %w", sheet, err)
+			}
+			col, _ := excelize.ColumnNumberToName(len(rows[0]) + 1)
+
+			f.SetCellValue(sheet, col+"1", "YoY Growth")
+			for row := 2; row <= len(rows); row++ {
+				assetType := rows[row-1][0]
+				if assetType == "TOTAL" {
+					continue
+				}
+				cell := fmt.Sprintf("%s%d", col, row)
+				if rate, ok := growth[assetType]; ok {
+					f.SetCellValue(sheet, cell, math.Round(rate*10000)/10000)
+					f.SetCellStyle(sheet, cell, cell, percent)
+				} else {
+					f.SetCellValue(sheet, cell, "N/A")
+				}
+			}
+			f.SetColWidth(sheet, col, col, 12)
+			copyRowStyle(f, sheet, 1, len(rows[0])+1, len(rows[0])+1)
+
+			first := fmt.Sprintf("%s2", col)
+			if err := f.SetConditionalFormat(sheet, fmt.Sprintf("%s:%s%d", first, col, len(rows)), []excelize.ConditionalFormatOptions{
+				{Type: "formula", Criteria: fmt.Sprintf("AND(ISNUMBER(%s),%s>0)", first, first), Format: &red},
+				{Type: "formula", Criteria: fmt.Sprintf("AND(ISNUMBER(%s),%s<0)", first, first), Format: &green},
+			}); err != nil {
+				return fmt.Errorf("failed to format YoY Growth column: %w", err)
+			}
+		}
+		return nil
+	}
+}
+
+// HighlightAssetRows returns a SheetWriter that sets a bold red font on the rows of the given
+// asset types in the combined asset sheet (Sheet1 or Summary), keeping each cell's other
+// formatting. Add it after any column writers so the whole row is covered.
+func HighlightAssetRows(types []string) SheetWriter {
+	highlight := make(map[string]bool, len(types))
+	for _, t := range types {
+		highlight[t] = true
+	}
+
+	return func(f *excelize.File) error {
+		for _, sheet := range assetSheets(f) {
+			if sheet != "Sheet1" && sheet != "Summary" {
+				continue
+			}
+			rows, err := f.GetRows(sheet)
+			if err != nil {
+				return fmt.Errorf("failed to read %s sheet: %w", sheet, err)
+			}
+
+			for row := 2; row <= len(rows); row++ {
+				if !highlight[rows[row-1][0]] {
+					continue
+				}
+				for col := 1; col <= len(rows[0]); col++ {
+					cell, _ := excelize.CoordinatesToCellName(col, row)
+					if err := setBoldRed(f, sheet, cell); err != nil {
+						return fmt.Errorf("failed to highlight %s!%s: %w", sheet, cell, err)
+					}
+				}
+			}
+		}
+		return nil
+	}
+}
+
+// setBoldRed adds a bold red font to a cell's existing style
+func setBoldRed(f *excelize.File, sheet, cell string) error {
+	styleID, err := f.GetCellStyle(sheet, cell)
+	if err != nil {
+		return err
+	}
+	style, err := f.GetStyle(styleID)
+	if err != nil {
+		return err
+	}
+	if style.Font == nil {
+		style.Font = &excelize.Font{}
+	}
+	style.Font.Bold = true
+	style.Font.Color = "C00000"
+
+	newID, err := f.NewStyle(style)
+	if err != nil {
+		return err
+	}
+	return f.SetCellStyle(sheet, cell, cell, newID)
+}
+
+// assetSheets returns the sheets written by writeAssetSheet
+func assetSheets(f *excelize.File) []string {
+	sheets := make([]string, 0)
+	for _, sheet := range f.GetSheetList() {
+		a1, _ := f.GetCellValue(sheet, "A1")
+		e1, _ := f.GetCellValue(sheet, "E1")
+		if a1 == "Asset Type" && e1 == "Synthetic Units" {
+			sheets = append(sheets, sheet)
+		}
+	}
+	return sheets
+}
+
+// copyRowStyle applies the style of a row's first cell to cells in the given column range
+func copyRowStyle(f *excelize.File, sheet string, row, fromCol, toCol int) {
+	if toCol < fromCol {
+		return
+	}
+	style, err := f.GetCellStyle(sheet, fmt.Sprintf("A%d", row))
+	if err != nil {
+		return
+	}
+	from, _ := excelize.CoordinatesToCellName(fromCol, row)
+	to, _ := excelize.CoordinatesToCellName(toCol, row)
+	f.SetCellStyle(sheet, from, to, style)
+}
@@ -0,0 +1,61 @@
+package output
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// CompressFile moves a file into a ZIP archive next to it and returns the archive path.
+// The original file is removed once the archive has been written.
+func CompressFile(filename, period string) (string, error) {
+	archivePath := strings.TrimSuffix(filename, filepath.Ext(filename)) + ".zip"
+
+	if err := writeZip(archivePath, filename, fmt.Sprintf("CloudCostCalaCLI report for period %s", period)); err != nil {
+		os.Remove(archivePath)
+		return "", err
+	}
+
+	if err := os.Remove(filename); err != nil {
+		return "", fmt.Errorf("failed to remove uncompressed file: %w", err)
+	}
+
+	return archivePath, nil
+}
+
+// writeZip writes a single-file ZIP archive with the given comment
+func writeZip(archivePath, filename, comment string) error {
+	in, err := os.Open(filename)
+	if err != nil {
+		return fmt.Errorf("failed to open file for compression: %w", err)
+	}
+	defer in.Close()
+
+	out, err := os.Create(archivePath)
+	if err != nil {
+		return fmt.Errorf("failed to create archive: %w", err)
+	}
+	defer out.Close()
+
+	zw := zip.NewWriter(out)
+	if err := zw.SetComment(comment); err != nil {
+		return fmt.Errorf("failed to set archive comment: %w", err)
+	}
+
+	w, err := zw.Create(filepath.Base(filename))
+	if err != nil {
+		return fmt.Errorf("failed to add file to archive: %w", err)
+	}
+	if _, err := io.Copy(w, in); err != nil {
+		return fmt.Errorf("failed to compress file: %w", err)
+	}
+
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to finalize archive: %w", err)
+	}
+
+	return nil
+}
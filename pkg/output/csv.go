@@ -0,0 +1,41 @@
+package output
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+
+	"github.com/ozwilder/CloudCostCalaCLI/internal/models"
+)
+
+// WriteRawCSV writes one row per billing record for auditing. Metadata entries become
+// meta_-prefixed columns; records without a given key leave that cell empty.
+func WriteRawCSV(filename string, records []models.BillingRecord) error {
+	// Metadata keys differ per record, so collect them all before writing the header
+	metaKeys := rawMetadataKeys(records)
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create raw CSV file: %w", err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+
+	if err := writer.Write(rawRecordHeader(metaKeys)); err != nil {
+		return fmt.Errorf("failed to write raw CSV header: %w", err)
+	}
+
+	for _, record := range records {
+		if err := writer.Write(rawRecordRow(record, metaKeys)); err != nil {
+			return fmt.Errorf("failed to write raw CSV row: %w", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write raw CSV file: %w", err)
+	}
+
+	return nil
+}
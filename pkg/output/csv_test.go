@@ -0,0 +1,64 @@
+package output
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ozwilder/CloudCostCalaCLI/internal/models"
+)
+
+func TestWriteRawCSVHeterogeneousMetadata(t *testing.T) {
+	records := []models.BillingRecord{
+		{ResourceID: "i-1", Metadata: map[string]string{"team": "payments"}},
+		{ResourceID: "i-2", Metadata: map[string]string{"env": "prod", "cost_center": "42"}},
+		{ResourceID: "i-3"},
+	}
+
+	path := filepath.Join(t.TempDir(), "raw.csv")
+	if err := WriteRawCSV(path, records); err != nil {
+		t.Fatalf("WriteRawCSV: %v", err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	rows, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatalf("reading raw CSV: %v", err)
+	}
+	if len(rows) != 4 {
+		t.Fatalf("got %d rows, want a header and 3 records", len(rows))
+	}
+
+	column := make(map[string]int)
+	for i, name := range rows[0] {
+		column[name] = i
+	}
+	for _, name := range []string{"meta_cost_center", "meta_env", "meta_team"} {
+		if _, ok := column[name]; !ok {
+			t.Errorf("header %v is missing %s", rows[0], name)
+		}
+	}
+
+	want := []map[string]string{
+		{"meta_team": "payments", "meta_env": "", "meta_cost_center": ""},
+		{"meta_team": "", "meta_env": "prod", "meta_cost_center": "42"},
+		{"meta_team": "", "meta_env": "", "meta_cost_center": ""},
+	}
+	for i, cells := range want {
+		row := rows[i+1]
+		if len(row) != len(rows[0]) {
+			t.Errorf("row %d has %d cells, header has %d", i+1, len(row), len(rows[0]))
+			continue
+		}
+		for name, value := range cells {
+			if got := row[column[name]]; got != value {
+				t.Errorf("row %d %s = %q, want %q", i+1, name, got, value)
+			}
+		}
+	}
+}
@@ -2,106 +2,341 @@ package output
 
 import (
 	"fmt"
+	"math"
+	"sort"
+	"strings"
 
 	"github.com/ozwilder/CloudCostCalaCLI/internal/models"
 	"github.com/xuri/excelize/v2"
 )
 
+// SheetWriter adds an extra sheet to a workbook before it is saved
+type SheetWriter func(f *excelize.File) error
+
 // WriteExcel generates an Excel file with aggregated asset data
-func WriteExcel(filename string, assets []models.AggregatedOutput) error {
+func WriteExcel(filename string, assets []models.AggregatedOutput, extra ...SheetWriter) error {
+	f := excelize.NewFile()
+
+	writeAssetSheet(f, "Sheet1", assets)
+
+	// Add charts
+	if err := AddChartsSheet(f, "Sheet1", assets); err != nil {
+		return err
+	}
+
+	// Add extra sheets
+	for _, write := range extra {
+		if err := write(f); err != nil {
+			return err
+		}
+	}
+
+	// Save file
+	if err := f.SaveAs(filename); err != nil {
+		return fmt.Errorf("failed to save Excel file: %w", err)
+	}
+
+	return nil
+}
+
+// WriteExcelByProvider generates an Excel file with a Summary sheet of the combined rows
+// followed by one sheet per provider
+func WriteExcelByProvider(filename string, summary []models.AggregatedOutput, byProvider map[string][]models.AggregatedOutput,
+	extra ...SheetWriter) error {
 	f := excelize.NewFile()
 
+	// Summary sheet replaces the default sheet so it opens first
+	if err := f.SetSheetName("Sheet1", "Summary"); err != nil {
+		return fmt.Errorf("failed to create Summary sheet: %w", err)
+	}
+	writeAssetSheet(f, "Summary", summary)
+
+	providers := make([]string, 0, len(byProvider))
+	for provider := range byProvider {
+		providers = append(providers, provider)
+	}
+	sort.Strings(providers)
+
+	for _, provider := range providers {
+		if _, err := f.NewSheet(provider); err != nil {
+			return fmt.Errorf("failed to create %s sheet: %w", provider, err)
+		}
+		writeAssetSheet(f, provider, byProvider[provider])
+	}
+
+	// Add charts of the combined rows
+	if err := AddChartsSheet(f, "Summary", summary); err != nil {
+		return err
+	}
+
+	// Add extra sheets
+	for _, write := range extra {
+		if err := write(f); err != nil {
+			return err
+		}
+	}
+
+	// Save file
+	if err := f.SaveAs(filename); err != nil {
+		return fmt.Errorf("failed to save Excel file: %w", err)
+	}
+
+	return nil
+}
+
+// writeAssetSheet writes the header, data rows and totals row for aggregated assets
+func writeAssetSheet(f *excelize.File, sheet string, assets []models.AggregatedOutput) {
 	// Create header
 	headers := []string{"Asset Type", "Current Count", "Ephemeral Count", "Avg Instances/Hr", "Synthetic Units"}
 	for i, header := range headers {
 		cell := fmt.Sprintf("%c1", 'A'+rune(i))
-		f.SetCellValue("Sheet1", cell, header)
-		
+		f.SetCellValue(sheet, cell, header)
+
 		// Bold header
 		style, _ := f.NewStyle(&excelize.Style{
 			Font: &excelize.Font{Bold: true},
 			Fill: excelize.Fill{Type: "pattern", Color: []string{"D3D3D3"}, Pattern: 1},
 		})
-		f.SetCellStyle("Sheet1", cell, cell, style)
+		f.SetCellStyle(sheet, cell, cell, style)
 	}
 
 	// Add data rows
 	for i, asset := range assets {
 		row := i + 2
-		f.SetCellValue("Sheet1", fmt.Sprintf("A%d", row), asset.AssetType)
-		f.SetCellValue("Sheet1", fmt.Sprintf("B%d", row), asset.CurrentCount)
-		f.SetCellValue("Sheet1", fmt.Sprintf("C%d", row), asset.EphemeralCount)
-		f.SetCellValue("Sheet1", fmt.Sprintf("D%d", row), fmt.Sprintf("%.2f", asset.AvgInstancesPerHour))
-		f.SetCellValue("Sheet1", fmt.Sprintf("E%d", row), asset.SyntheticUnits)
+		f.SetCellValue(sheet, fmt.Sprintf("A%d", row), asset.AssetType)
+		f.SetCellValue(sheet, fmt.Sprintf("B%d", row), asset.CurrentCount)
+		f.SetCellValue(sheet, fmt.Sprintf("C%d", row), asset.EphemeralCount)
+		f.SetCellValue(sheet, fmt.Sprintf("D%d", row), fmt.Sprintf("%.2f", asset.AvgInstancesPerHour))
+		f.SetCellValue(sheet, fmt.Sprintf("E%d", row), asset.SyntheticUnits)
 	}
 
 	// Adjust column widths
-	f.SetColWidth("Sheet1", "A", "A", 15)
-	f.SetColWidth("Sheet1", "B", "B", 15)
-	f.SetColWidth("Sheet1", "C", "C", 16)
-	f.SetColWidth("Sheet1", "D", "D", 18)
-	f.SetColWidth("Sheet1", "E", "E", 15)
+	f.SetColWidth(sheet, "A", "A", 15)
+	f.SetColWidth(sheet, "B", "B", 15)
+	f.SetColWidth(sheet, "C", "C", 16)
+	f.SetColWidth(sheet, "D", "D", 18)
+	f.SetColWidth(sheet, "E", "E", 15)
+
+	headerStyle, _ := f.GetCellStyle(sheet, "A1")
+	lastCol := 'E'
+
+	// Estimated cost column when pricing is configured
+	currency := pricingCurrency(assets)
+	costCol := rune(0)
+	if currency != "" {
+		lastCol++
+		costCol = lastCol
+		f.SetCellValue(sheet, fmt.Sprintf("%c1", costCol), fmt.Sprintf("Estimated Cost (%s)", currency))
+		f.SetCellStyle(sheet, fmt.Sprintf("%c1", costCol), fmt.Sprintf("%c1", costCol), headerStyle)
+		for i, asset := range assets {
+			f.SetCellValue(sheet, fmt.Sprintf("%c%d", costCol, i+2), math.Round(asset.EstimatedCost*100)/100)
+		}
+		f.SetColWidth(sheet, string(costCol), string(costCol), 22)
+	}
+
+	// Billed cost per deployment when the inventory has deployment counts
+	deploymentCol := rune(0)
+	if hasDeployments(assets) {
+		deploymentCol = lastCol + 1
+		lastCol += 3
+		for j, header := range []string{"Deployments", "Billed Cost", "Cost/Deployment"} {
+			cell := fmt.Sprintf("%c1", deploymentCol+rune(j))
+			f.SetCellValue(sheet, cell, header)
+			f.SetCellStyle(sheet, cell, cell, headerStyle)
+		}
+		for i, asset := range assets {
+			row := i + 2
+			f.SetCellValue(sheet, fmt.Sprintf("%c%d", deploymentCol, row), asset.DeploymentCount)
+			f.SetCellValue(sheet, fmt.Sprintf("%c%d", deploymentCol+1, row), math.Round(asset.BilledCost*100)/100)
+			if asset.DeploymentCount > 0 {
+				f.SetCellValue(sheet, fmt.Sprintf("%c%d", deploymentCol+2, row), math.Round(asset.CostPerDeployment*100)/100)
+			} else {
+				f.SetCellValue(sheet, fmt.Sprintf("%c%d", deploymentCol+2, row), "N/A")
+			}
+		}
+		f.SetColWidth(sheet, string(deploymentCol), string(lastCol), 16)
+	}
 
 	// Add totals row
 	if len(assets) > 0 {
 		totalRow := len(assets) + 2
-		f.SetCellValue("Sheet1", fmt.Sprintf("A%d", totalRow), "TOTAL")
-		
+		f.SetCellValue(sheet, fmt.Sprintf("A%d", totalRow), "TOTAL")
+
 		// Sum formulas
-		f.SetCellFormula("Sheet1", fmt.Sprintf("B%d", totalRow), fmt.Sprintf("SUM(B2:B%d)", totalRow-1))
-		f.SetCellFormula("Sheet1", fmt.Sprintf("C%d", totalRow), fmt.Sprintf("SUM(C2:C%d)", totalRow-1))
-		f.SetCellFormula("Sheet1", fmt.Sprintf("D%d", totalRow), fmt.Sprintf("SUM(D2:D%d)", totalRow-1))
-		f.SetCellFormula("Sheet1", fmt.Sprintf("E%d", totalRow), fmt.Sprintf("SUM(E2:E%d)", totalRow-1))
-		
+		f.SetCellFormula(sheet, fmt.Sprintf("B%d", totalRow), fmt.Sprintf("SUM(B2:B%d)", totalRow-1))
+		f.SetCellFormula(sheet, fmt.Sprintf("C%d", totalRow), fmt.Sprintf("SUM(C2:C%d)", totalRow-1))
+		f.SetCellFormula(sheet, fmt.Sprintf("D%d", totalRow), fmt.Sprintf("SUM(D2:D%d)", totalRow-1))
+		f.SetCellFormula(sheet, fmt.Sprintf("E%d", totalRow), fmt.Sprintf("SUM(E2:E%d)", totalRow-1))
+		if costCol != 0 {
+			f.SetCellFormula(sheet, fmt.Sprintf("%c%d", costCol, totalRow), fmt.Sprintf("SUM(%c2:%c%d)", costCol, costCol, totalRow-1))
+		}
+		if deploymentCol != 0 {
+			deployments, billed := deploymentCol, deploymentCol+1
+			f.SetCellFormula(sheet, fmt.Sprintf("%c%d", deployments, totalRow), fmt.Sprintf("SUM(%c2:%c%d)", deployments, deployments, totalRow-1))
+			f.SetCellFormula(sheet, fmt.Sprintf("%c%d", billed, totalRow), fmt.Sprintf("SUM(%c2:%c%d)", billed, billed, totalRow-1))
+			f.SetCellFormula(sheet, fmt.Sprintf("%c%d", deploymentCol+2, totalRow),
+				fmt.Sprintf(`IF(%c%d>0,ROUND(%c%d/%c%d,2),"N/A")`, deployments, totalRow, billed, totalRow, deployments, totalRow))
+		}
+
 		// Bold totals row
 		boldStyle, _ := f.NewStyle(&excelize.Style{
 			Font: &excelize.Font{Bold: true},
 			Fill: excelize.Fill{Type: "pattern", Color: []string{"FFFF00"}, Pattern: 1},
 		})
-		for col := 'A'; col <= 'E'; col++ {
-			f.SetCellStyle("Sheet1", fmt.Sprintf("%c%d", col, totalRow), fmt.Sprintf("%c%d", col, totalRow), boldStyle)
+		for col := 'A'; col <= lastCol; col++ {
+			f.SetCellStyle(sheet, fmt.Sprintf("%c%d", col, totalRow), fmt.Sprintf("%c%d", col, totalRow), boldStyle)
 		}
 	}
+}
 
-	// Save file
-	if err := f.SaveAs(filename); err != nil {
-		return fmt.Errorf("failed to save Excel file: %w", err)
+// WriteGroupSheet writes aggregated rows for each named group to a new sheet, followed by
+// a totals row per group summing its synthetic units and costs
+func WriteGroupSheet(f *excelize.File, sheet string, byGroup map[string][]models.AggregatedOutput) error {
+	if _, err := f.NewSheet(sheet); err != nil {
+		return fmt.Errorf("failed to create %s sheet: %w", sheet, err)
+	}
+
+	// Estimated cost column when pricing is configured
+	var all []models.AggregatedOutput
+	for _, rows := range byGroup {
+		all = append(all, rows...)
+	}
+	currency := pricingCurrency(all)
+
+	headers := []string{"Group", "Asset Type", "Current Count", "Ephemeral Count", "Avg Instances/Hr", "Synthetic Units", "Billed Cost"}
+	if currency != "" {
+		headers = append(headers, fmt.Sprintf("Estimated Cost (%s)", currency))
+	}
+	lastCol := 'A' + rune(len(headers)-1)
+	style, _ := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true},
+		Fill: excelize.Fill{Type: "pattern", Color: []string{"D3D3D3"}, Pattern: 1},
+	})
+	for i, header := range headers {
+		cell := fmt.Sprintf("%c1", 'A'+rune(i))
+		f.SetCellValue(sheet, cell, header)
+		f.SetCellStyle(sheet, cell, cell, style)
 	}
+	totalStyle, _ := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+
+	groups := make([]string, 0, len(byGroup))
+	for group := range byGroup {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+
+	row := 2
+	for _, group := range groups {
+		first := row
+		for _, asset := range byGroup[group] {
+			f.SetCellValue(sheet, fmt.Sprintf("A%d", row), group)
+			f.SetCellValue(sheet, fmt.Sprintf("B%d", row), asset.AssetType)
+			f.SetCellValue(sheet, fmt.Sprintf("C%d", row), asset.CurrentCount)
+			f.SetCellValue(sheet, fmt.Sprintf("D%d", row), asset.EphemeralCount)
+			f.SetCellValue(sheet, fmt.Sprintf("E%d", row), fmt.Sprintf("%.2f", asset.AvgInstancesPerHour))
+			f.SetCellValue(sheet, fmt.Sprintf("F%d", row), asset.SyntheticUnits)
+			f.SetCellValue(sheet, fmt.Sprintf("G%d", row), math.Round(asset.BilledCost*100)/100)
+			if currency != "" {
+				f.SetCellValue(sheet, fmt.Sprintf("H%d", row), math.Round(asset.EstimatedCost*100)/100)
+			}
+			row++
+		}
+
+		f.SetCellValue(sheet, fmt.Sprintf("A%d", row), group+" Total")
+		for col := 'F'; col <= lastCol; col++ {
+			f.SetCellFormula(sheet, fmt.Sprintf("%c%d", col, row), fmt.Sprintf("SUM(%c%d:%c%d)", col, first, col, row-1))
+		}
+		f.SetCellStyle(sheet, fmt.Sprintf("A%d", row), fmt.Sprintf("%c%d", lastCol, row), totalStyle)
+		row++
+	}
+
+	f.SetColWidth(sheet, "A", "B", 18)
+	f.SetColWidth(sheet, "C", string(lastCol), 16)
 
 	return nil
 }
 
+// hasDeployments reports whether any row has a known deployment count
+func hasDeployments(assets []models.AggregatedOutput) bool {
+	for _, asset := range assets {
+		if asset.DeploymentCount > 0 {
+			return true
+		}
+	}
+	return false
+}
+
 // PrintSummaryTable prints asset data to console
 func PrintSummaryTable(assets []models.AggregatedOutput) {
-	fmt.Println("\n╔════════════════╦════════════════╦════════════════╦════════════════╦════════════════╗")
-	fmt.Println("║  Asset Type    ║ Current Count  ║ Ephemeral Cnt  ║ Avg Inst/Hr    ║ Synthetic Unts ║")
-	fmt.Println("╠════════════════╬════════════════╬════════════════╬════════════════╬════════════════╣")
+	// Estimated cost column when pricing is configured
+	currency := pricingCurrency(assets)
+	columns := 5
+	if currency != "" {
+		columns = 6
+	}
+
+	fmt.Println("\n" + tableBorder("╔", "╦", "╗", columns))
+	header := "║  Asset Type    ║ Current Count  ║ Ephemeral Cnt  ║ Avg Inst/Hr    ║ Synthetic Unts ║"
+	if currency != "" {
+		header += fmt.Sprintf(" %-14s ║", "Est. Cost "+currency)
+	}
+	fmt.Println(header)
+	fmt.Println(tableBorder("╠", "╬", "╣", columns))
 
 	totalCurrent := 0
 	totalEphemeral := 0
 	totalAvgInstances := 0.0
 	totalUnits := 0
+	totalCost := 0.0
 
 	for _, asset := range assets {
-		fmt.Printf("║ %-14s ║ %14d ║ %14d ║ %14.2f ║ %14d ║\n",
+		fmt.Printf("║ %-14s ║ %14d ║ %14d ║ %14.2f ║ %14d ║",
 			asset.AssetType,
 			asset.CurrentCount,
 			asset.EphemeralCount,
 			asset.AvgInstancesPerHour,
 			asset.SyntheticUnits)
+		if currency != "" {
+			fmt.Printf(" %14.2f ║", asset.EstimatedCost)
+		}
+		fmt.Println()
 
 		totalCurrent += asset.CurrentCount
 		totalEphemeral += asset.EphemeralCount
 		totalAvgInstances += asset.AvgInstancesPerHour
 		totalUnits += asset.SyntheticUnits
+		totalCost += asset.EstimatedCost
 	}
 
-	fmt.Println("╠════════════════╬════════════════╬════════════════╬════════════════╬════════════════╣")
-	fmt.Printf("║ %-14s ║ %14d ║ %14d ║ %14.2f ║ %14d ║\n",
+	fmt.Println(tableBorder("╠", "╬", "╣", columns))
+	fmt.Printf("║ %-14s ║ %14d ║ %14d ║ %14.2f ║ %14d ║",
 		"TOTAL",
 		totalCurrent,
 		totalEphemeral,
 		totalAvgInstances,
 		totalUnits)
-	fmt.Println("╚════════════════╩════════════════╩════════════════╩════════════════╩════════════════╝\n")
+	if currency != "" {
+		fmt.Printf(" %14.2f ║", totalCost)
+	}
+	fmt.Println()
+	fmt.Print(tableBorder("╚", "╩", "╝", columns) + "\n\n")
+}
+
+// tableBorder draws a horizontal summary table border for the given number of columns
+func tableBorder(left, mid, right string, columns int) string {
+	cells := make([]string, columns)
+	for i := range cells {
+		cells[i] = strings.Repeat("═", 16)
+	}
+	return left + strings.Join(cells, mid) + right
+}
+
+// pricingCurrency returns the currency of priced rows, or "" when pricing is not configured
+func pricingCurrency(assets []models.AggregatedOutput) string {
+	for _, asset := range assets {
+		if asset.Currency != "" {
+			return asset.Currency
+		}
+	}
+	return ""
 }
@@ -0,0 +1,146 @@
+package output
+
+import (
+	"archive/zip"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ozwilder/CloudCostCalaCLI/internal/models"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestWriteGroupSheetCosts(t *testing.T) {
+	byGroup := map[string][]models.AggregatedOutput{
+		"core": {
+			{AssetType: "VM", SyntheticUnits: 10, BilledCost: 100.5, EstimatedCost: 20, Currency: "USD"},
+			{AssetType: "Database", SyntheticUnits: 5, BilledCost: 49.5, EstimatedCost: 10, Currency: "USD"},
+		},
+		"edge": {
+			{AssetType: "VM", SyntheticUnits: 2, BilledCost: 7, EstimatedCost: 4, Currency: "USD"},
+		},
+	}
+
+	f := excelize.NewFile()
+	if err := WriteGroupSheet(f, "By VPC Group", byGroup); err != nil {
+		t.Fatalf("WriteGroupSheet: %v", err)
+	}
+
+	cells := map[string]string{
+		"G1": "Billed Cost",
+		"H1": "Estimated Cost (USD)",
+		"A2": "core", "G2": "100.5", "H2": "20",
+		"A4": "core Total",
+		"A5": "edge", "G5": "7",
+		"A6": "edge Total",
+	}
+	for cell, want := range cells {
+		if got, _ := f.GetCellValue("By VPC Group", cell); got != want {
+			t.Errorf("%s = %q, want %q", cell, got, want)
+		}
+	}
+
+	formulas := map[string]string{
+		"F4": "SUM(F2:F3)", "G4": "SUM(G2:G3)", "H4": "SUM(H2:H3)",
+		"G6": "SUM(G5:G5)",
+	}
+	for cell, want := range formulas {
+		if got, _ := f.GetCellFormula("By VPC Group", cell); got != want {
+			t.Errorf("%s formula = %q, want %q", cell, got, want)
+		}
+	}
+}
+
+func TestWriteGroupSheetWithoutPricing(t *testing.T) {
+	f := excelize.NewFile()
+	byGroup := map[string][]models.AggregatedOutput{"core": {{AssetType: "VM", BilledCost: 3}}}
+	if err := WriteGroupSheet(f, "By VPC Group", byGroup); err != nil {
+		t.Fatalf("WriteGroupSheet: %v", err)
+	}
+	if got, _ := f.GetCellValue("By VPC Group", "H1"); got != "" {
+		t.Errorf("H1 = %q, want no estimated cost column without pricing", got)
+	}
+}
+
+func TestWriteAssetSheetCostPerDeployment(t *testing.T) {
+	assets := []models.AggregatedOutput{
+		{AssetType: "VM", SyntheticUnits: 10, EstimatedCost: 20, Currency: "USD", DeploymentCount: 4, BilledCost: 100, CostPerDeployment: 25},
+		{AssetType: "Database", SyntheticUnits: 5, EstimatedCost: 10, Currency: "USD"},
+	}
+
+	f := excelize.NewFile()
+	writeAssetSheet(f, "Sheet1", assets)
+
+	cells := map[string]string{
+		"F1": "Estimated Cost (USD)", "G1": "Deployments", "H1": "Billed Cost", "I1": "Cost/Deployment",
+		"I2": "25", "I3": "N/A",
+		"A4": "TOTAL",
+	}
+	for cell, want := range cells {
+		if got, _ := f.GetCellValue("Sheet1", cell); got != want {
+			t.Errorf("%s = %q, want %q", cell, got, want)
+		}
+	}
+
+	formulas := map[string]string{
+		"F4": "SUM(F2:F3)",
+		"G4": "SUM(G2:G3)",
+		"H4": "SUM(H2:H3)",
+		"I4": `IF(G4>0,ROUND(H4/G4,2),"N/A")`,
+	}
+	for cell, want := range formulas {
+		if got, _ := f.GetCellFormula("Sheet1", cell); got != want {
+			t.Errorf("%s formula = %q, want %q", cell, got, want)
+		}
+	}
+}
+
+func TestWriteAssetSheetWithoutDeployments(t *testing.T) {
+	f := excelize.NewFile()
+	writeAssetSheet(f, "Sheet1", []models.AggregatedOutput{{AssetType: "VM", SyntheticUnits: 1}})
+	if got, _ := f.GetCellValue("Sheet1", "F1"); got != "" {
+		t.Errorf("F1 = %q, want no cost columns without pricing or deployments", got)
+	}
+}
+
+func TestWriteExcelByProviderAddsCharts(t *testing.T) {
+	summary := []models.AggregatedOutput{{AssetType: "VM", CurrentCount: 3, SyntheticUnits: 15}}
+	byProvider := map[string][]models.AggregatedOutput{
+		"AWS":   {{AssetType: "VM", CurrentCount: 2, SyntheticUnits: 10}},
+		"Azure": {{AssetType: "VM", CurrentCount: 1, SyntheticUnits: 5}},
+	}
+	path := filepath.Join(t.TempDir(), "out.xlsx")
+	if err := WriteExcelByProvider(path, summary, byProvider); err != nil {
+		t.Fatalf("WriteExcelByProvider: %v", err)
+	}
+
+	f, err := excelize.OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if !slices.Contains(f.GetSheetList(), "Charts") {
+		t.Fatalf("sheets = %v, want a Charts sheet", f.GetSheetList())
+	}
+
+	// The charts must plot the Summary sheet, not the renamed Sheet1
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer archive.Close()
+	chart, err := archive.Open("xl/charts/chart1.xml")
+	if err != nil {
+		t.Fatalf("no chart written: %v", err)
+	}
+	defer chart.Close()
+	xml, err := io.ReadAll(chart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(xml), "&#39;Summary&#39;!$E$2:$E$2") || strings.Contains(string(xml), "Sheet1") {
+		t.Errorf("chart does not reference the Summary rows:\n%s", xml)
+	}
+}
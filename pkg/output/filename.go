@@ -0,0 +1,35 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// FilenameTimestampFormat is the layout of the Timestamp variable in output filename templates
+const FilenameTimestampFormat = "20060102-150405"
+
+// FilenameData holds the variables available to output.outputFilenameTemplate
+type FilenameData struct {
+	Period    string // Billing period, e.g. 2024-01
+	Timestamp string // Run time in FilenameTimestampFormat
+	Provider  string // Provider with billing data, or "all" when there are several
+}
+
+// RenderFilename evaluates an output filename template such as
+// "cloud-costs-{{.Period}}-{{.Timestamp}}.xlsx"
+func RenderFilename(tmpl string, data FilenameData) (string, error) {
+	t, err := template.New("filename").Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("invalid output filename template: %w", err)
+	}
+
+	var b strings.Builder
+	if err := t.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("failed to render output filename template: %w", err)
+	}
+	if strings.TrimSpace(b.String()) == "" {
+		return "", fmt.Errorf("output filename template %q rendered an empty filename", tmpl)
+	}
+	return b.String(), nil
+}
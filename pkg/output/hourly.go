@@ -0,0 +1,130 @@
+package output
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/ozwilder/CloudCostCalaCLI/internal/models"
+	"github.com/xuri/excelize/v2"
+)
+
+// WriteUsageByHourSheet adds a "Usage by Hour" sheet with one row per hour of day, one
+// instance-hours column per asset type plus a total, and a bar chart of the total
+func WriteUsageByHourSheet(f *excelize.File, byType map[string][24]float64) error {
+	sheet := "Usage by Hour"
+	if _, err := f.NewSheet(sheet); err != nil {
+		return fmt.Errorf("failed to create %s sheet: %w", sheet, err)
+	}
+
+	types := make([]string, 0, len(byType))
+	for assetType := range byType {
+		types = append(types, assetType)
+	}
+	sort.Strings(types)
+
+	headers := append([]string{"Hour"}, types...)
+	headers = append(headers, "Total")
+	style, _ := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true},
+		Fill: excelize.Fill{Type: "pattern", Color: []string{"D3D3D3"}, Pattern: 1},
+	})
+	for i, header := range headers {
+		cell, _ := excelize.CoordinatesToCellName(i+1, 1)
+		f.SetCellValue(sheet, cell, header)
+		f.SetCellStyle(sheet, cell, cell, style)
+	}
+
+	for hour := 0; hour < 24; hour++ {
+		row := hour + 2
+		f.SetCellValue(sheet, fmt.Sprintf("A%d", row), fmt.Sprintf("%02d:00", hour))
+
+		total := 0.0
+		for i, assetType := range types {
+			cell, _ := excelize.CoordinatesToCellName(i+2, row)
+			f.SetCellValue(sheet, cell, byType[assetType][hour])
+			total += byType[assetType][hour]
+		}
+		cell, _ := excelize.CoordinatesToCellName(len(headers), row)
+		f.SetCellValue(sheet, cell, total)
+	}
+
+	totalCol, _ := excelize.ColumnNumberToName(len(headers))
+	f.SetColWidth(sheet, "A", totalCol, 14)
+
+	chartCell, _ := excelize.CoordinatesToCellName(len(headers)+2, 1)
+	if err := f.AddChart(sheet, chartCell, &excelize.Chart{
+		Type: excelize.Col,
+		Series: []excelize.ChartSeries{
+			{
+				Name:       fmt.Sprintf("'%s'!$%s$1", sheet, totalCol),
+				Categories: fmt.Sprintf("'%s'!$A$2:$A$25", sheet),
+				Values:     fmt.Sprintf("'%s'!$%s$2:$%s$25", sheet, totalCol, totalCol),
+			},
+		},
+		Title:     []excelize.RichTextRun{{Text: "Instance-Hours by Hour of Day"}},
+		Legend:    excelize.ChartLegend{Position: "none"},
+		Dimension: excelize.ChartDimension{Width: 640, Height: 320},
+	}); err != nil {
+		return fmt.Errorf("failed to add usage by hour chart: %w", err)
+	}
+
+	return nil
+}
+
+// WriteProviderHeatMap adds a sheet with a day-of-week × hour-of-day grid of the average
+// instance-hours per record starting in that hour, shaded from white (low) to dark blue (high).
+// Records without a start time are left out.
+func WriteProviderHeatMap(f *excelize.File, sheetName string, records []models.BillingRecord) error {
+	if _, err := f.NewSheet(sheetName); err != nil {
+		return fmt.Errorf("failed to create %s sheet: %w", sheetName, err)
+	}
+
+	// Rows are Monday..Sunday, columns hours 0..23
+	var sums, counts [7][24]float64
+	for _, record := range records {
+		if record.StartTime.IsZero() {
+			continue
+		}
+		day := (int(record.StartTime.Weekday()) + 6) % 7
+		sums[day][record.StartTime.Hour()] += record.InstanceHours
+		counts[day][record.StartTime.Hour()]++
+	}
+
+	style, _ := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true},
+		Fill: excelize.Fill{Type: "pattern", Color: []string{"D3D3D3"}, Pattern: 1},
+	})
+	f.SetCellValue(sheetName, "A1", "Day / Hour")
+	for hour := 0; hour < 24; hour++ {
+		cell, _ := excelize.CoordinatesToCellName(hour+2, 1)
+		f.SetCellValue(sheetName, cell, hour)
+	}
+	f.SetCellStyle(sheetName, "A1", "Y1", style)
+
+	days := []string{"Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday", "Sunday"}
+	for day, name := range days {
+		row := day + 2
+		f.SetCellValue(sheetName, fmt.Sprintf("A%d", row), name)
+		f.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("A%d", row), style)
+		for hour := 0; hour < 24; hour++ {
+			average := 0.0
+			if counts[day][hour] > 0 {
+				average = sums[day][hour] / counts[day][hour]
+			}
+			cell, _ := excelize.CoordinatesToCellName(hour+2, row)
+			f.SetCellValue(sheetName, cell, math.Round(average*100)/100)
+		}
+	}
+
+	f.SetColWidth(sheetName, "A", "A", 14)
+	f.SetColWidth(sheetName, "B", "Y", 6)
+
+	if err := f.SetConditionalFormat(sheetName, "B2:Y8", []excelize.ConditionalFormatOptions{
+		{Type: "2_color_scale", Criteria: "=", MinType: "min", MaxType: "max", MinColor: "#FFFFFF", MaxColor: "#08306B"},
+	}); err != nil {
+		return fmt.Errorf("failed to format %s heat map: %w", sheetName, err)
+	}
+
+	return nil
+}
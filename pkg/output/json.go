@@ -0,0 +1,55 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ozwilder/CloudCostCalaCLI/internal/models"
+)
+
+// WriteJSON writes aggregated asset data to a JSON file, indented with two spaces when
+// indent is set and compact otherwise
+func WriteJSON(filename string, assets []models.AggregatedOutput, indent bool) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create JSON file: %w", err)
+	}
+	defer file.Close()
+
+	return encodeJSON(file, assets, indent)
+}
+
+// ReadJSON reads aggregated asset data written by WriteJSON
+func ReadJSON(filename string) ([]models.AggregatedOutput, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JSON file: %w", err)
+	}
+
+	var assets []models.AggregatedOutput
+	if err := json.Unmarshal(data, &assets); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON file: %w", err)
+	}
+
+	return assets, nil
+}
+
+// EncodeJSON writes aggregated asset data as indented JSON to w
+func EncodeJSON(w io.Writer, assets []models.AggregatedOutput) error {
+	return encodeJSON(w, assets, true)
+}
+
+// encodeJSON writes aggregated asset data as indented or compact JSON to w
+func encodeJSON(w io.Writer, assets []models.AggregatedOutput, indent bool) error {
+	encoder := json.NewEncoder(w)
+	if indent {
+		encoder.SetIndent("", "  ")
+	}
+	if err := encoder.Encode(assets); err != nil {
+		return fmt.Errorf("failed to encode JSON output: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,32 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/ozwilder/CloudCostCalaCLI/internal/models"
+)
+
+// WriteLineage writes a JSON sidecar mapping each asset type to the billing file rows,
+// per provider, that contributed to its synthetic units
+func WriteLineage(path string, aggregated []models.AggregatedOutput) error {
+	lineage := make(map[string]map[string][]int, len(aggregated))
+	for _, a := range aggregated {
+		rows := a.SourceRows
+		if rows == nil {
+			rows = make(map[string][]int)
+		}
+		lineage[a.AssetType] = rows
+	}
+
+	data, err := json.MarshalIndent(lineage, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode lineage: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write lineage file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,41 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// MetadataSuffix is appended to the output filename to name the run metadata sidecar
+const MetadataSuffix = ".meta.json"
+
+// RunMetadata describes a single run for audit trails and automated checks
+type RunMetadata struct {
+	ToolVersion         string         `json:"tool_version"`
+	RunTimestamp        time.Time      `json:"run_timestamp"`
+	BillingPeriod       string         `json:"billing_period"`
+	RecordsByProvider   map[string]int `json:"records_by_provider"`
+	TotalSyntheticUnits int            `json:"total_synthetic_units"`
+	Warnings            []string       `json:"warnings"`
+}
+
+// WriteMetadata writes run metadata as a sidecar JSON file next to outputFile
+// and returns the sidecar's path
+func WriteMetadata(outputFile string, meta RunMetadata) (string, error) {
+	if meta.Warnings == nil {
+		meta.Warnings = make([]string, 0)
+	}
+
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode run metadata: %w", err)
+	}
+
+	path := outputFile + MetadataSuffix
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return "", fmt.Errorf("failed to write run metadata file: %w", err)
+	}
+
+	return path, nil
+}
@@ -0,0 +1,114 @@
+package output
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ozwilder/CloudCostCalaCLI/internal/models"
+	"github.com/xuri/excelize/v2"
+)
+
+// WriteExcelMultiPeriod generates an Excel file with one column group per billing period
+func WriteExcelMultiPeriod(filename string, byPeriod map[string][]models.AggregatedOutput) error {
+	f := excelize.NewFile()
+	sheet := "Sheet1"
+	periods := sortedPeriods(byPeriod)
+
+	headerStyle, _ := f.NewStyle(&excelize.Style{
+		Font:      &excelize.Font{Bold: true},
+		Fill:      excelize.Fill{Type: "pattern", Color: []string{"D3D3D3"}, Pattern: 1},
+		Alignment: &excelize.Alignment{Horizontal: "center"},
+	})
+
+	// Two header rows: period labels spanning their column group, then metric names
+	f.SetCellValue(sheet, "A1", "Asset Type")
+	f.MergeCell(sheet, "A1", "A2")
+	for i, period := range periods {
+		first, _ := excelize.CoordinatesToCellName(2+i*2, 1)
+		last, _ := excelize.CoordinatesToCellName(3+i*2, 1)
+		f.SetCellValue(sheet, first, period)
+		f.MergeCell(sheet, first, last)
+
+		avgCell, _ := excelize.CoordinatesToCellName(2+i*2, 2)
+		unitsCell, _ := excelize.CoordinatesToCellName(3+i*2, 2)
+		f.SetCellValue(sheet, avgCell, "Avg Instances/Hr")
+		f.SetCellValue(sheet, unitsCell, "Synthetic Units")
+	}
+	lastHeader, _ := excelize.CoordinatesToCellName(1+len(periods)*2, 2)
+	f.SetCellStyle(sheet, "A1", lastHeader, headerStyle)
+
+	// One row per asset type seen in any period
+	types := make([]string, 0)
+	seen := make(map[string]bool)
+	byTypePeriod := make(map[string]map[string]models.AggregatedOutput)
+	for _, period := range periods {
+		for _, asset := range byPeriod[period] {
+			if !seen[asset.AssetType] {
+				seen[asset.AssetType] = true
+				types = append(types, asset.AssetType)
+				byTypePeriod[asset.AssetType] = make(map[string]models.AggregatedOutput)
+			}
+			byTypePeriod[asset.AssetType][period] = asset
+		}
+	}
+	sort.Strings(types)
+
+	for r, assetType := range types {
+		row := r + 3
+		f.SetCellValue(sheet, fmt.Sprintf("A%d", row), assetType)
+		for i, period := range periods {
+			asset := byTypePeriod[assetType][period]
+			avgCell, _ := excelize.CoordinatesToCellName(2+i*2, row)
+			unitsCell, _ := excelize.CoordinatesToCellName(3+i*2, row)
+			f.SetCellValue(sheet, avgCell, fmt.Sprintf("%.2f", asset.AvgInstancesPerHour))
+			f.SetCellValue(sheet, unitsCell, asset.SyntheticUnits)
+		}
+	}
+
+	// Totals row for synthetic units in each period
+	if len(types) > 0 {
+		totalRow := len(types) + 3
+		f.SetCellValue(sheet, fmt.Sprintf("A%d", totalRow), "TOTAL")
+		for i := range periods {
+			col, _ := excelize.ColumnNumberToName(3 + i*2)
+			f.SetCellFormula(sheet, fmt.Sprintf("%s%d", col, totalRow), fmt.Sprintf("SUM(%s3:%s%d)", col, col, totalRow-1))
+		}
+
+		boldStyle, _ := f.NewStyle(&excelize.Style{
+			Font: &excelize.Font{Bold: true},
+			Fill: excelize.Fill{Type: "pattern", Color: []string{"FFFF00"}, Pattern: 1},
+		})
+		lastTotal, _ := excelize.CoordinatesToCellName(1+len(periods)*2, totalRow)
+		f.SetCellStyle(sheet, fmt.Sprintf("A%d", totalRow), lastTotal, boldStyle)
+	}
+
+	lastCol, _ := excelize.ColumnNumberToName(1 + len(periods)*2)
+	f.SetColWidth(sheet, "A", "A", 15)
+	f.SetColWidth(sheet, "B", lastCol, 18)
+
+	// Save file
+	if err := f.SaveAs(filename); err != nil {
+		return fmt.Errorf("failed to save Excel file: %w", err)
+	}
+
+	return nil
+}
+
+// PrintMultiPeriodSummary prints one summary table per billing period
+func PrintMultiPeriodSummary(byPeriod map[string][]models.AggregatedOutput) {
+	for _, period := range sortedPeriods(byPeriod) {
+		fmt.Printf("\nPeriod: %s", period)
+		PrintSummaryTable(byPeriod[period])
+	}
+}
+
+// sortedPeriods returns the period keys in chronological order
+func sortedPeriods(byPeriod map[string][]models.AggregatedOutput) []string {
+	periods := make([]string, 0, len(byPeriod))
+	for period := range byPeriod {
+		periods = append(periods, period)
+	}
+	// YYYY-MM sorts chronologically as a string
+	sort.Strings(periods)
+	return periods
+}
@@ -0,0 +1,87 @@
+package output
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ozwilder/CloudCostCalaCLI/internal/models"
+)
+
+// WritePRComment renders a GitHub Markdown table of the asset types added, removed and
+// changed in synthetic units between prev and curr, for a pull request comment or job summary
+func WritePRComment(prev, curr []models.AggregatedOutput, period string) string {
+	prevUnits := unitsByType(prev)
+	currUnits := unitsByType(curr)
+
+	types := make([]string, 0, len(prevUnits)+len(currUnits))
+	for assetType := range prevUnits {
+		types = append(types, assetType)
+	}
+	for assetType := range currUnits {
+		if _, exists := prevUnits[assetType]; !exists {
+			types = append(types, assetType)
+		}
+	}
+	sort.Strings(types)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "## Synthetic units report (%s)\n\n", period)
+
+	unchanged := 0
+	prevTotal, currTotal := 0, 0
+	rows := make([]string, 0, len(types))
+	for _, assetType := range types {
+		before, wasBilled := prevUnits[assetType]
+		after, isBilled := currUnits[assetType]
+		prevTotal += before
+		currTotal += after
+
+		switch {
+		case !wasBilled:
+			rows = append(rows, fmt.Sprintf("| 🆕 | %s | – | %d | %s |", assetType, after, formatUnitChange(0, after)))
+		case !isBilled:
+			rows = append(rows, fmt.Sprintf("| ❌ | %s | %d | – | %s |", assetType, before, formatUnitChange(before, 0)))
+		case after > before:
+			rows = append(rows, fmt.Sprintf("| 🔺 | %s | %d | %d | %s |", assetType, before, after, formatUnitChange(before, after)))
+		case after < before:
+			rows = append(rows, fmt.Sprintf("| 🔻 | %s | %d | %d | %s |", assetType, before, after, formatUnitChange(before, after)))
+		default:
+			unchanged++
+		}
+	}
+
+	if len(rows) == 0 {
+		b.WriteString("✅ No changes in synthetic units.\n")
+	} else {
+		b.WriteString("| | Asset Type | Previous | Current | Change |\n")
+		b.WriteString("|---|---|--:|--:|--:|\n")
+		for _, row := range rows {
+			b.WriteString(row + "\n")
+		}
+		if unchanged > 0 {
+			fmt.Fprintf(&b, "\n%d asset type(s) unchanged.\n", unchanged)
+		}
+	}
+
+	fmt.Fprintf(&b, "\n**Total synthetic units:** %d → %d, %s\n", prevTotal, currTotal, formatUnitChange(prevTotal, currTotal))
+	return b.String()
+}
+
+// unitsByType indexes synthetic units by asset type
+func unitsByType(aggregated []models.AggregatedOutput) map[string]int {
+	units := make(map[string]int, len(aggregated))
+	for _, a := range aggregated {
+		units[a.AssetType] += a.SyntheticUnits
+	}
+	return units
+}
+
+// formatUnitChange formats a change in units with its sign, and the percentage when there was a previous value
+func formatUnitChange(before, after int) string {
+	change := fmt.Sprintf("%+d", after-before)
+	if before == 0 {
+		return change
+	}
+	return fmt.Sprintf("%s (%+.1f%%)", change, float64(after-before)/float64(before)*100)
+}
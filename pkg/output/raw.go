@@ -0,0 +1,107 @@
+package output
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/ozwilder/CloudCostCalaCLI/internal/models"
+	"github.com/xuri/excelize/v2"
+)
+
+// MaxRawRecordRows caps the Raw Records sheet (header included) for older Excel versions
+const MaxRawRecordRows = 65535
+
+// rawRecordFields lists the BillingRecord fields written before the metadata columns
+var rawRecordFields = []string{"service_name", "resource_type", "resource_id", "instance_hours",
+	"cost", "time_period", "region", "project", "account_id"}
+
+// WriteRawRecordsSheet adds a "Raw Records" sheet with one row per billing record.
+// Records beyond MaxRawRecordRows are left out.
+func WriteRawRecordsSheet(f *excelize.File, records []models.BillingRecord) error {
+	sheet := "Raw Records"
+	if _, err := f.NewSheet(sheet); err != nil {
+		return fmt.Errorf("failed to create %s sheet: %w", sheet, err)
+	}
+
+	if len(records) > MaxRawRecordRows-1 {
+		records = records[:MaxRawRecordRows-1]
+	}
+	metaKeys := rawMetadataKeys(records)
+
+	header := rawRecordHeader(metaKeys)
+	if err := f.SetSheetRow(sheet, "A1", &header); err != nil {
+		return fmt.Errorf("failed to write %s header: %w", sheet, err)
+	}
+	style, _ := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true},
+		Fill: excelize.Fill{Type: "pattern", Color: []string{"D3D3D3"}, Pattern: 1},
+	})
+	lastHeader, _ := excelize.CoordinatesToCellName(len(header), 1)
+	f.SetCellStyle(sheet, "A1", lastHeader, style)
+
+	for i, record := range records {
+		row := rawRecordRow(record, metaKeys)
+		values := make([]interface{}, len(row))
+		for j, value := range row {
+			values[j] = value
+		}
+		values[3] = record.InstanceHours // Keep instance-hours and cost numeric
+		values[4] = record.Cost
+
+		cell, _ := excelize.CoordinatesToCellName(1, i+2)
+		if err := f.SetSheetRow(sheet, cell, &values); err != nil {
+			return fmt.Errorf("failed to write %s row %d: %w", sheet, i+2, err)
+		}
+	}
+
+	lastCol, _ := excelize.ColumnNumberToName(len(header))
+	f.SetColWidth(sheet, "A", lastCol, 16)
+
+	return nil
+}
+
+// rawMetadataKeys returns every metadata key used by any record, sorted
+func rawMetadataKeys(records []models.BillingRecord) []string {
+	keySet := make(map[string]bool)
+	for _, record := range records {
+		for key := range record.Metadata {
+			keySet[key] = true
+		}
+	}
+
+	keys := make([]string, 0, len(keySet))
+	for key := range keySet {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// rawRecordHeader returns the record field names followed by meta_-prefixed metadata keys
+func rawRecordHeader(metaKeys []string) []string {
+	header := append([]string{}, rawRecordFields...)
+	for _, key := range metaKeys {
+		header = append(header, "meta_"+key)
+	}
+	return header
+}
+
+// rawRecordRow returns a record's values in rawRecordHeader order
+func rawRecordRow(record models.BillingRecord, metaKeys []string) []string {
+	row := []string{
+		record.ServiceName,
+		record.ResourceType,
+		record.ResourceID,
+		strconv.FormatFloat(record.InstanceHours, 'f', -1, 64),
+		strconv.FormatFloat(record.Cost, 'f', -1, 64),
+		record.TimePeriod,
+		record.Region,
+		record.Project,
+		record.AccountID,
+	}
+	for _, key := range metaKeys {
+		row = append(row, record.Metadata[key])
+	}
+	return row
+}
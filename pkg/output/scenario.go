@@ -0,0 +1,73 @@
+package output
+
+import (
+	"fmt"
+
+	"github.com/ozwilder/CloudCostCalaCLI/internal/models"
+	"github.com/xuri/excelize/v2"
+)
+
+// WriteScenarioSheet adds a "Scenario" sheet comparing base and simulated synthetic units
+// per asset type. scenario must list the base types first, in the same order.
+func WriteScenarioSheet(f *excelize.File, base, scenario []models.AggregatedOutput) error {
+	sheet := "Scenario"
+	if _, err := f.NewSheet(sheet); err != nil {
+		return fmt.Errorf("failed to create %s sheet: %w", sheet, err)
+	}
+
+	headers := []string{"Asset Type", "Base Instances/Hr", "Scenario Instances/Hr", "Base Units", "Scenario Units", "Change"}
+	style, _ := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true},
+		Fill: excelize.Fill{Type: "pattern", Color: []string{"D3D3D3"}, Pattern: 1},
+	})
+	for i, header := range headers {
+		cell := fmt.Sprintf("%c1", 'A'+rune(i))
+		f.SetCellValue(sheet, cell, header)
+		f.SetCellStyle(sheet, cell, cell, style)
+	}
+
+	for i, s := range scenario {
+		var b models.AggregatedOutput
+		if i < len(base) {
+			b = base[i]
+		}
+		row := i + 2
+		f.SetCellValue(sheet, fmt.Sprintf("A%d", row), s.AssetType)
+		f.SetCellValue(sheet, fmt.Sprintf("B%d", row), fmt.Sprintf("%.2f", b.AvgInstancesPerHour))
+		f.SetCellValue(sheet, fmt.Sprintf("C%d", row), fmt.Sprintf("%.2f", s.AvgInstancesPerHour))
+		f.SetCellValue(sheet, fmt.Sprintf("D%d", row), b.SyntheticUnits)
+		f.SetCellValue(sheet, fmt.Sprintf("E%d", row), s.SyntheticUnits)
+		f.SetCellValue(sheet, fmt.Sprintf("F%d", row), s.SyntheticUnits-b.SyntheticUnits)
+	}
+
+	if len(scenario) > 0 {
+		totalRow := len(scenario) + 2
+		f.SetCellValue(sheet, fmt.Sprintf("A%d", totalRow), "TOTAL")
+		for _, col := range []string{"D", "E", "F"} {
+			f.SetCellFormula(sheet, fmt.Sprintf("%s%d", col, totalRow), fmt.Sprintf("SUM(%s2:%s%d)", col, col, totalRow-1))
+		}
+		f.SetCellStyle(sheet, fmt.Sprintf("A%d", totalRow), fmt.Sprintf("F%d", totalRow), style)
+	}
+
+	f.SetColWidth(sheet, "A", "A", 15)
+	f.SetColWidth(sheet, "B", "C", 22)
+	f.SetColWidth(sheet, "D", "F", 15)
+
+	return nil
+}
+
+// PrintScenario prints the base and simulated synthetic units per asset type
+func PrintScenario(base, scenario []models.AggregatedOutput) {
+	fmt.Println("\n=== Scenario ===")
+	baseTotal, scenarioTotal := 0, 0
+	for i, s := range scenario {
+		var b models.AggregatedOutput
+		if i < len(base) {
+			b = base[i]
+		}
+		fmt.Printf("  %-15s %6d → %6d (%+d)\n", s.AssetType, b.SyntheticUnits, s.SyntheticUnits, s.SyntheticUnits-b.SyntheticUnits)
+		baseTotal += b.SyntheticUnits
+		scenarioTotal += s.SyntheticUnits
+	}
+	fmt.Printf("  %-15s %6d → %6d (%+d)\n", "TOTAL", baseTotal, scenarioTotal, scenarioTotal-baseTotal)
+}
@@ -0,0 +1,72 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ozwilder/CloudCostCalaCLI/internal/models"
+)
+
+// SummaryFormats lists the accepted console summary formats; the first is the default
+var SummaryFormats = []string{"unicode", "plain", "json"}
+
+// PrintSummary prints the aggregated summary to the console in the given format
+func PrintSummary(assets []models.AggregatedOutput, format string) error {
+	switch format {
+	case "", "unicode":
+		PrintSummaryTable(assets)
+		return nil
+	case "plain":
+		PrintSummaryPlain(assets)
+		return nil
+	case "json":
+		return PrintSummaryJSON(assets)
+	default:
+		return fmt.Errorf("unknown summary format %q (expected one of: %s)", format, strings.Join(SummaryFormats, ", "))
+	}
+}
+
+// PrintSummaryPlain prints asset data as an ASCII table for terminals and logs without Unicode
+func PrintSummaryPlain(assets []models.AggregatedOutput) {
+	currency := pricingCurrency(assets)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	header := "Asset Type\tCurrent Count\tEphemeral Count\tAvg Inst/Hr\tSynthetic Units\t"
+	if currency != "" {
+		header += "Est. Cost " + currency + "\t"
+	}
+	fmt.Fprintln(os.Stdout)
+	fmt.Fprintln(w, header)
+
+	totalCurrent, totalEphemeral, totalUnits := 0, 0, 0
+	totalAvgInstances, totalCost := 0.0, 0.0
+	for _, asset := range assets {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%.2f\t%d\t", asset.AssetType, asset.CurrentCount, asset.EphemeralCount,
+			asset.AvgInstancesPerHour, asset.SyntheticUnits)
+		if currency != "" {
+			fmt.Fprintf(w, "%.2f\t", asset.EstimatedCost)
+		}
+		fmt.Fprintln(w)
+
+		totalCurrent += asset.CurrentCount
+		totalEphemeral += asset.EphemeralCount
+		totalAvgInstances += asset.AvgInstancesPerHour
+		totalUnits += asset.SyntheticUnits
+		totalCost += asset.EstimatedCost
+	}
+
+	fmt.Fprintf(w, "TOTAL\t%d\t%d\t%.2f\t%d\t", totalCurrent, totalEphemeral, totalAvgInstances, totalUnits)
+	if currency != "" {
+		fmt.Fprintf(w, "%.2f\t", totalCost)
+	}
+	fmt.Fprintln(w)
+	w.Flush()
+	fmt.Println()
+}
+
+// PrintSummaryJSON prints the aggregated output as indented JSON for scripted consumption
+func PrintSummaryJSON(assets []models.AggregatedOutput) error {
+	return EncodeJSON(os.Stdout, assets)
+}
@@ -0,0 +1,52 @@
+package output
+
+import (
+	"fmt"
+
+	"github.com/ozwilder/CloudCostCalaCLI/internal/analysis"
+	"github.com/xuri/excelize/v2"
+)
+
+// WriteTagsSheet adds a "Tags" sheet listing suggested tags per resource
+func WriteTagsSheet(f *excelize.File, suggestions []analysis.TagSuggestion) error {
+	sheet := "Tags"
+	if _, err := f.NewSheet(sheet); err != nil {
+		return fmt.Errorf("failed to create %s sheet: %w", sheet, err)
+	}
+
+	headers := []string{"Resource ID", "Service", "Tag", "Suggested Value"}
+	style, _ := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true},
+		Fill: excelize.Fill{Type: "pattern", Color: []string{"D3D3D3"}, Pattern: 1},
+	})
+	for i, header := range headers {
+		cell := fmt.Sprintf("%c1", 'A'+rune(i))
+		f.SetCellValue(sheet, cell, header)
+		f.SetCellStyle(sheet, cell, cell, style)
+	}
+
+	for i, s := range suggestions {
+		row := i + 2
+		f.SetCellValue(sheet, fmt.Sprintf("A%d", row), s.ResourceID)
+		f.SetCellValue(sheet, fmt.Sprintf("B%d", row), s.ServiceName)
+		f.SetCellValue(sheet, fmt.Sprintf("C%d", row), s.Tag)
+		f.SetCellValue(sheet, fmt.Sprintf("D%d", row), s.Value)
+	}
+
+	f.SetColWidth(sheet, "A", "A", 28)
+	f.SetColWidth(sheet, "B", "D", 18)
+
+	return nil
+}
+
+// PrintTagSuggestions prints the suggested tags report to the console
+func PrintTagSuggestions(suggestions []analysis.TagSuggestion) {
+	fmt.Println("\n=== Suggested Tags ===")
+	if len(suggestions) == 0 {
+		fmt.Println("No tag suggestions")
+		return
+	}
+	for _, s := range suggestions {
+		fmt.Printf("  %-28s %s=%s\n", s.ResourceID, s.Tag, s.Value)
+	}
+}
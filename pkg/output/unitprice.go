@@ -0,0 +1,39 @@
+package output
+
+import (
+	"fmt"
+
+	"github.com/ozwilder/CloudCostCalaCLI/internal/analysis"
+	"github.com/xuri/excelize/v2"
+)
+
+// WriteUnitPriceSheet adds a "Unit Price List" sheet with the billed cost per synthetic unit
+// of each asset type and region, in the order given
+func WriteUnitPriceSheet(f *excelize.File, prices []analysis.UnitPrice) error {
+	sheet := "Unit Price List"
+	if _, err := f.NewSheet(sheet); err != nil {
+		return fmt.Errorf("failed to create %s sheet: %w", sheet, err)
+	}
+
+	headers := []string{"Asset Type", "Region", "Synthetic Units", "Billed Cost", "Cost per Unit"}
+	style, _ := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true},
+		Fill: excelize.Fill{Type: "pattern", Color: []string{"D3D3D3"}, Pattern: 1},
+	})
+	for i, header := range headers {
+		cell := fmt.Sprintf("%c1", 'A'+rune(i))
+		f.SetCellValue(sheet, cell, header)
+		f.SetCellStyle(sheet, cell, cell, style)
+	}
+
+	for i, p := range prices {
+		row := i + 2
+		f.SetCellValue(sheet, fmt.Sprintf("A%d", row), p.AssetType)
+		f.SetCellValue(sheet, fmt.Sprintf("B%d", row), p.Region)
+		f.SetCellValue(sheet, fmt.Sprintf("C%d", row), fmt.Sprintf("%.2f", p.SyntheticUnits))
+		f.SetCellValue(sheet, fmt.Sprintf("D%d", row), fmt.Sprintf("%.2f", p.BilledCost))
+		f.SetCellValue(sheet, fmt.Sprintf("E%d", row), fmt.Sprintf("%.4f", p.CostPerUnit))
+	}
+
+	return nil
+}
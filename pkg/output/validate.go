@@ -0,0 +1,58 @@
+package output
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/ozwilder/CloudCostCalaCLI/internal/models"
+	"github.com/xuri/excelize/v2"
+)
+
+// ValidateExcel reads an asset sheet back from a saved workbook and checks that its rows
+// match expected: same asset types in the same order, with equal Synthetic Units values.
+// Every mismatch is reported.
+func ValidateExcel(filename, sheet string, expected []models.AggregatedOutput) error {
+	f, err := excelize.OpenFile(filename)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", filename, err)
+	}
+	defer f.Close()
+
+	rows, err := f.GetRows(sheet)
+	if err != nil {
+		return fmt.Errorf("failed to read %s sheet: %w", sheet, err)
+	}
+
+	// Skip the header and the TOTAL row
+	data := make([][]string, 0, len(rows))
+	for _, row := range rows[min(1, len(rows)):] {
+		if len(row) > 0 && row[0] == "TOTAL" {
+			continue
+		}
+		data = append(data, row)
+	}
+
+	if len(data) != len(expected) {
+		return fmt.Errorf("%s sheet: expected %d data rows, found %d", sheet, len(expected), len(data))
+	}
+
+	errs := make([]error, 0)
+	for i, a := range expected {
+		row := data[i]
+		if len(row) < 5 {
+			errs = append(errs, fmt.Errorf("%s row %d: missing Synthetic Units column", sheet, i+2))
+			continue
+		}
+		if row[0] != a.AssetType {
+			errs = append(errs, fmt.Errorf("%s row %d: expected asset type %q, found %q", sheet, i+2, a.AssetType, row[0]))
+			continue
+		}
+		units, err := strconv.Atoi(row[4])
+		if err != nil || units != a.SyntheticUnits {
+			errs = append(errs, fmt.Errorf("%s row %d (%s): expected %d synthetic units, found %q", sheet, i+2, a.AssetType, a.SyntheticUnits, row[4]))
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,41 @@
+package sdk_test
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/ozwilder/CloudCostCalaCLI/pkg/cloudcost"
+	"github.com/ozwilder/CloudCostCalaCLI/pkg/sdk"
+)
+
+func ExampleAnalyze() {
+	dir, err := os.MkdirTemp("", "sdk-example")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	billingFile := filepath.Join(dir, "aws.csv")
+	csv := "service,resourceId,instanceHours,period,region\nEC2,i-1,744,2024-01,us-east-1\n"
+	if err := os.WriteFile(billingFile, []byte(csv), 0644); err != nil {
+		log.Fatal(err)
+	}
+
+	cfg := &cloudcost.Config{}
+	cfg.Billing.AWS.FilePath = billingFile
+	cfg.SyntheticUnits.Rules = map[string]cloudcost.SyntheticUnitRule{"VM": {UnitsPerInstance: 4}}
+
+	rows, err := sdk.Analyze(cfg, sdk.AnalysisOptions{
+		ParseOptions: sdk.ParseOptions{Masking: cloudcost.FieldMaskingConfig{MaskResourceID: true}},
+		Types:        []string{"VM"},
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, row := range rows {
+		fmt.Printf("%s: %.2f instances/hr, %d synthetic units\n", row.AssetType, row.AvgInstancesPerHour, row.SyntheticUnits)
+	}
+	// Output: VM: 1.00 instances/hr, 4 synthetic units
+}
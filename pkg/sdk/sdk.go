@@ -0,0 +1,77 @@
+// Package sdk is a one-call API over the CloudCostCalaCLI pipeline for services that embed
+// the synthetic-unit calculation:
+//
+//	cfg, err := cloudcost.LoadConfig("config.json")
+//	if err != nil {
+//		return err
+//	}
+//	rows, err := sdk.Analyze(cfg, sdk.AnalysisOptions{Types: []string{"VM"}})
+//
+// Use NewPipeline instead of Analyze to also read the parsed records afterwards.
+package sdk
+
+import (
+	"context"
+
+	"github.com/ozwilder/CloudCostCalaCLI/pkg/cloudcost"
+)
+
+// ParseOptions controls how billing files are read
+type ParseOptions = cloudcost.ParseOptions
+
+// AnalysisOptions configures an analysis; the zero value reads the config's billing files
+// with default parse options, no inventory and no filters
+type AnalysisOptions struct {
+	Context                context.Context // Defaults to context.Background()
+	ParseOptions           ParseOptions
+	Inventory              []cloudcost.Asset
+	InstanceHoursPrecision *int     // Decimal places to round instance-hours to; nil leaves them unrounded
+	Types                  []string // Asset types to restrict processing to (case-insensitive)
+	EphemeralOnly          bool     // Keep only types billed but absent from Inventory
+	BillingPeriodAuto      bool     // Use the most common record period rather than the first record's
+	Hooks                  cloudcost.Hooks
+}
+
+// Analyze parses, normalizes, enriches and aggregates the billing files named in cfg and
+// returns one row per asset type
+func Analyze(cfg *cloudcost.Config, opts AnalysisOptions) ([]cloudcost.AggregatedOutput, error) {
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return NewPipeline(cfg, opts).Run(ctx)
+}
+
+// NewPipeline builds the pipeline Analyze runs, for callers that also need its records,
+// billing period or inventory after Run
+func NewPipeline(cfg *cloudcost.Config, opts AnalysisOptions) *cloudcost.Pipeline {
+	parseOpts := opts.ParseOptions
+	if parseOpts.GCPFormat == "" {
+		parseOpts.GCPFormat = cfg.Billing.GCP.Format
+	}
+	if parseOpts.SkipRows == nil {
+		parseOpts.SkipRows = cfg.Billing.SkipRows()
+	}
+	if parseOpts.AzureTimezone == "" {
+		parseOpts.AzureTimezone = cfg.Billing.Azure.Timezone
+	}
+	if parseOpts.Masking == (cloudcost.FieldMaskingConfig{}) {
+		parseOpts.Masking = cfg.FieldMasking
+	}
+
+	pipelineOpts := []cloudcost.Option{
+		cloudcost.WithBillingParser(cloudcost.FileParser{Options: parseOpts}),
+		cloudcost.WithTypeFilter(opts.Types),
+		cloudcost.WithEphemeralOnly(opts.EphemeralOnly),
+		cloudcost.WithBillingPeriodAuto(opts.BillingPeriodAuto),
+		cloudcost.WithHooks(opts.Hooks),
+	}
+	if opts.Inventory != nil {
+		pipelineOpts = append(pipelineOpts, cloudcost.WithInventory(opts.Inventory))
+	}
+	if opts.InstanceHoursPrecision != nil {
+		pipelineOpts = append(pipelineOpts, cloudcost.WithInstanceHoursPrecision(*opts.InstanceHoursPrecision))
+	}
+
+	return cloudcost.NewPipeline(cfg, pipelineOpts...)
+}